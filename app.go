@@ -2,52 +2,115 @@ package appctx
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"os/signal"
-	"syscall"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
 
 	"github.com/rs/zerolog"
 )
 
 type appCfg[T any, U any] struct {
-	Debug bool `yaml:"debug"`
+	Debug    bool         `yaml:"debug"`
+	Timeouts appTimeouts  `yaml:"timeouts"`
+	Health   appHealthCfg `yaml:"health"`
 
 	Custom  T `yaml:",inline"`
 	Plugins U `yaml:",inline"`
 }
 
+type appPhase int32
+
+const (
+	appStarting appPhase = iota
+	appRunning
+	appStopping
+	appStopped
+)
+
 type AppCtx[T any, U any] struct {
 	context.Context
 
-	cfg    appCfg[T, U]
-	logger zerolog.Logger
+	cfg         *appCfg[T, U]
+	custom      *atomic.Pointer[T]
+	cfgDefaults appCfg[T, U]
+	logger      *atomic.Pointer[zerolog.Logger]
+	logWriter   *appLogWriter
+	logOutputs  []io.Writer
 
-	configFile        string
+	configFiles       []string
+	environment       string
+	optionalConfig    bool
 	title             string
 	version           string
 	hasLogger         bool
 	hasError          bool
+	err               error
 	noFlags           bool
 	noConfig          bool
-	cancel            func()
+	noStrictConfig    bool
+	concurrentStart   bool
+	cancel            context.CancelCauseFunc
 	flags             []appFlag
-	registeredPlugins []AppPlugin[T, U]
+	flagGroup         string
+	flagValues        []appFlagValue
+	args              []string
+	envPrefix         string
+	overrides         []string
+	sources           *atomic.Pointer[map[string]ConfigSource]
+	commands          []*AppCommand[T, U]
+	command           *AppCommand[T, U]
+	registeredPlugins []appPluginRegistration[T, U]
+	plugins           []*appPluginEntry[T, U]
+	watchdog          *shutdownWatchdog
+	health            *appHealth
+	phase             *atomic.Int32
+	workers           *appWorkers
+	services          *appServices[T, U]
+	root              *AppCtx[T, U]
+	reloadMu          *sync.Mutex
+	cfgMu             *sync.RWMutex
 }
 
 func NewApp[T any, U any](title, version string) *AppCtx[T, U] {
-	return &AppCtx[T, U]{
-		title:   title,
-		version: version,
-	}
+	return newApp[T, U](title, version)
 }
 
 func NewAppWithContext[T any, U any](ctx context.Context, title, version string) *AppCtx[T, U] {
-	return &AppCtx[T, U]{
-		Context: ctx,
-		title:   title,
-		version: version,
+	app := newApp[T, U](title, version)
+	app.Context = ctx
+	return app
+}
+
+func newApp[T any, U any](title, version string) *AppCtx[T, U] {
+	app := &AppCtx[T, U]{
+		title:    title,
+		version:  version,
+		cfg:      &appCfg[T, U]{},
+		custom:   &atomic.Pointer[T]{},
+		health:   &appHealth{},
+		phase:    &atomic.Int32{},
+		workers:  &appWorkers{},
+		services: &appServices[T, U]{},
+		reloadMu: &sync.Mutex{},
+		cfgMu:    &sync.RWMutex{},
+		sources:  &atomic.Pointer[map[string]ConfigSource]{},
+		logger:   &atomic.Pointer[zerolog.Logger]{},
 	}
+
+	// derived copies of the app refer to the original one, so that long-living
+	// goroutines are not bound to short-lived contexts of the copies
+	app.root = app
+	app.custom.Store(&app.cfg.Custom)
+	app.logger.Store(&zerolog.Logger{})
+
+	app.cfg.Timeouts.Shutdown = defaultShutdownTimeout
+	app.cfg.Health.Timeout = defaultHealthTimeout
+	return app
 }
 
 func (app *AppCtx[T, U]) MarshalZerologObject(e *zerolog.Event) {
@@ -58,14 +121,31 @@ func (app *AppCtx[T, U]) MarshalZerologObject(e *zerolog.Event) {
 	}
 
 	if app.noConfig {
-		e.Bool("no_config", true)
+		e = e.Bool("no_config", true)
+	}
+
+	if app.noStrictConfig {
+		e = e.Bool("no_strict_config", true)
+	}
+
+	if app.concurrentStart {
+		e = e.Bool("concurrent_start", true)
+	}
+
+	if app.environment != "" {
+		e.Str("environment", app.environment)
 	}
 }
 
+// Config returns the custom part of the app config. Reload does not modify it,
+// but publishes a new one instead, so the returned value is safe to read at any time
+// and should be requested again to observe reloaded values.
 func (app *AppCtx[T, U]) Config() *T {
-	return &app.cfg.Custom
+	return app.custom.Load()
 }
 
+// Plugins returns the plugins part of the app config. Unlike Config, it is modified in place:
+// Reload updates fields of plugins which have accepted the new config in PluginReconfigure.
 func (app *AppCtx[T, U]) Plugins() *U {
 	return &app.cfg.Plugins
 }
@@ -86,40 +166,102 @@ func (app *AppCtx[T, U]) Version() string {
 	return app.version
 }
 
+// Environment returns the environment selected by --env flag or APP_ENV variable.
+func (app *AppCtx[T, U]) Environment() string {
+	return app.environment
+}
+
+// Ready reports whether all plugins have started and shutdown has not begun yet.
+func (app *AppCtx[T, U]) Ready() bool {
+	return appPhase(app.phase.Load()) == appRunning
+}
+
+// ShuttingDown reports whether the app has begun or finished its shutdown.
+func (app *AppCtx[T, U]) ShuttingDown() bool {
+	return appPhase(app.phase.Load()) >= appStopping
+}
+
+// advancePhase moves the app to the given lifecycle phase unless it is already past it.
+func (app *AppCtx[T, U]) advancePhase(phase appPhase) {
+	for {
+		cur := app.phase.Load()
+		if appPhase(cur) >= phase || app.phase.CompareAndSwap(cur, int32(phase)) {
+			return
+		}
+	}
+}
+
+// Run starts plugins and runs the callback along with services registered by AddService.
+// Callback may be nil if the app consists of services only.
 func (app *AppCtx[T, U]) Run(callback func(ctx *AppCtx[T, U]) error) {
-	if app.Context == nil {
-		app.Context, app.cancel = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	} else {
-		app.Context, app.cancel = context.WithCancel(app.Context)
+	_ = app.RunE(callback)
+}
+
+// RunE is like Run, but also returns the final error of the app, which determines
+// the exit code used by Exit (see ExitCodeOf).
+func (app *AppCtx[T, U]) RunE(callback func(ctx *AppCtx[T, U]) error) error {
+	handleSignals := app.Context == nil
+	if handleSignals {
+		app.Context = context.Background()
+	}
+
+	app.Context, app.cancel = context.WithCancelCause(app.Context)
+	defer app.cancel(ErrFinished)
+
+	if handleSignals {
+		app.watchSignals()
 	}
 
-	defer app.cancel()
+	stopWatch := context.AfterFunc(app.Context, func() {
+		app.advancePhase(appStopping)
+	})
+	defer stopWatch()
 
-	defer app.stopPlugins()
+	err := app.runRecovering(callback)
+	app.advancePhase(appStopping)
 
-	err := app.run(callback)
+	// workers exit on context cancellation, and plugins should be stopped only after that
 	if err != nil {
-		app.hasError = true
-		if app.hasLogger {
-			app.logger.Err(err).Msg("shutting down")
-		} else {
-			fmt.Println("ERROR: " + err.Error())
-		}
+		app.cancel(err)
 	} else {
-		app.logger.Info().Msg("shutting down")
+		app.cancel(ErrFinished)
 	}
-}
 
-func (app *AppCtx[_, _]) Stop() {
-	app.logger.Debug().Msg("app stop requested")
+	if app.watchdog != nil {
+		app.watchdog.arm()
+		defer app.watchdog.stop()
+	}
 
-	if app.cancel != nil {
-		app.cancel()
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+	case err != nil:
+		app.logError(err, "shutting down")
+	default:
+		app.Logger().Info().AnErr("cause", app.StopCause()).Msg("shutting down")
 	}
+
+	err = errors.Join(err, app.workers.wait())
+
+	// app may be stopped with a cause which indicates failure even if nothing has returned an error
+	if cause := app.StopCause(); err == nil && !isGracefulStop(cause) {
+		err = cause
+	}
+
+	stopErr := app.stopPlugins()
+	if stopErr != nil {
+		app.logError(stopErr, "failed to stop plugins")
+	}
+
+	app.advancePhase(appStopped)
+
+	app.err = errors.Join(err, stopErr)
+	app.hasError = app.err != nil
+	return app.err
 }
 
-func (app *AppCtx[T, U]) Exit() {
-	os.Exit(map[bool]int{false: 0, true: 1}[app.hasError])
+// Stop stops the app with ErrStopRequested cause.
+func (app *AppCtx[_, _]) Stop() {
+	app.StopWithCause(ErrStopRequested)
 }
 
 func (app *AppCtx[T, U]) DisableFlags() {
@@ -130,37 +272,99 @@ func (app *AppCtx[T, U]) DisableConfig() {
 	app.noConfig = true
 }
 
+// AllowMissingConfig makes missing config files fall back to defaults instead of failing.
+func (app *AppCtx[T, U]) AllowMissingConfig() {
+	app.optionalConfig = true
+}
+
 func (app *AppCtx[T, U]) run(callback func(ctx *AppCtx[T, U]) error) error {
 	setDefault(&app.title, "App")
 	setDefault(&app.version, "0.0.1")
 
 	app.Flag2("d", "debug", &app.cfg.Debug, false, "enable debug output")
-	app.Flag2("c", "config-file", &app.configFile, "config.yml", "path to config file")
+	app.Flag2("c", "config-file", &app.configFiles, []string{"config.yml"}, "path to config file or directory (repeatable)")
+	app.Flag("env", &app.environment, os.Getenv("APP_ENV"), "environment whose config overlays are applied, e.g. production (default $APP_ENV)")
+	app.Flag("set", &app.overrides, []string(nil), "override config value, e.g. --set timeouts.start=10s (repeatable)")
+
+	err := app.orderPlugins()
+	if err != nil {
+		return ExitError{Code: ExitCodePluginStart, Err: fmt.Errorf("ordering plugins: %w", err)}
+	}
+
+	err = app.instantiatePlugins()
+	if err != nil {
+		return ExitError{Code: ExitCodePluginStart, Err: fmt.Errorf("instantiating plugins: %w", err)}
+	}
+
+	err = app.registerPluginFlags()
+	if err != nil {
+		return ExitError{Code: ExitCodePluginStart, Err: err}
+	}
 
-	err := app.instantiatePlugins()
+	err = app.registerTagFlags()
 	if err != nil {
-		return fmt.Errorf("instantiating plugins: %w", err)
+		return err
 	}
 
 	err = app.initFlags()
 	if err != nil {
-		return fmt.Errorf("initializing flags: %w", err)
+		return err
+	}
+
+	callback, err = app.commandCallback(callback)
+	if err != nil {
+		return err
 	}
 
-	err = app.loadConfig(app.configFile)
+	err = app.selectPlugins()
+	if err != nil {
+		return ExitError{Code: ExitCodePluginStart, Err: fmt.Errorf("selecting plugins: %w", err)}
+	}
+
+	// values set so far (by plugins, flags or directly) serve as defaults for config reloads
+	app.saveDefaults()
+
+	err = app.loadConfig()
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return ExitError{Code: ExitCodeConfig, Err: err}
+	}
+
+	err = app.validateConfig(app.liveConfig())
+	if err != nil {
+		return ExitError{Code: ExitCodeConfig, Err: fmt.Errorf("validating config: %w", err)}
 	}
 
 	app.makeLogger()
+	app.watchdog = app.watchShutdown()
 
 	err = app.startPlugins()
 	if err != nil {
-		return fmt.Errorf("starting plugins: %w", err)
+		return ExitError{Code: ExitCodePluginStart, Err: fmt.Errorf("starting plugins: %w", err)}
 	}
 
+	app.watchHealth()
+	app.advancePhase(appRunning)
+
 	app.Log().EmbedObject(app).Msg("app: running")
-	return callback(app)
+	return app.runServices(callback)
+}
+
+// runRecovering calls run, converting panics which happen outside of plugin hooks
+// and services into PanicError.
+func (app *AppCtx[T, U]) runRecovering(callback func(ctx *AppCtx[T, U]) error) (err error) {
+	defer func() {
+		e := recover()
+		if e != nil {
+			err = &PanicError{
+				Value: e,
+				Stack: debug.Stack(),
+			}
+
+			app.logPanic(err, "stage", "startup")
+		}
+	}()
+
+	return app.run(callback)
 }
 
 func (app *AppCtx[T, U]) clone() *AppCtx[T, U] {
@@ -0,0 +1,131 @@
+package appctx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+var (
+	// ErrFinished is the stop cause of an app whose Run callback has returned.
+	ErrFinished = errors.New("app finished")
+	// ErrStopRequested is the stop cause of an app stopped by Stop.
+	ErrStopRequested = errors.New("app stop requested")
+)
+
+// SignalError is the stop cause of an app stopped by an OS signal.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return "received signal " + e.Signal.String()
+}
+
+// PluginError is returned when a plugin hook fails.
+type PluginError struct {
+	Op   string
+	Name string
+	Err  error
+}
+
+func (e *PluginError) Error() string {
+	return fmt.Sprintf("%v plugin \"%v\": %v", e.Op, e.Name, e.Err)
+}
+
+func (e *PluginError) Unwrap() error {
+	return e.Err
+}
+
+// WorkerError is the stop cause of an app stopped by a failure of a fatal worker.
+type WorkerError struct {
+	Name string
+	Err  error
+}
+
+func (e *WorkerError) Error() string {
+	return fmt.Sprintf("worker \"%v\": %v", e.Name, e.Err)
+}
+
+func (e *WorkerError) Unwrap() error {
+	return e.Err
+}
+
+// ServiceError is the stop cause of an app stopped by a failure of a service.
+type ServiceError struct {
+	Name string
+	Err  error
+}
+
+func (e *ServiceError) Error() string {
+	return fmt.Sprintf("service \"%v\": %v", e.Name, e.Err)
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// StopWithCause stops the app recording the reason, which is then available from StopCause.
+// Only the first cause is recorded if the app is stopped several times.
+func (app *AppCtx[_, _]) StopWithCause(cause error) {
+	app.Logger().Debug().AnErr("cause", cause).Msg("app stop requested")
+
+	if app.cancel != nil {
+		app.cancel(cause)
+	}
+}
+
+// StopCause returns the reason why the app was stopped, or nil if it is still running.
+func (app *AppCtx[_, _]) StopCause() error {
+	if app.Context == nil || app.Err() == nil {
+		return nil
+	}
+
+	return context.Cause(app.Context)
+}
+
+// isGracefulStop reports whether the stop cause does not indicate a failure.
+func isGracefulStop(cause error) bool {
+	var sigErr *SignalError
+	return cause == nil ||
+		errors.Is(cause, ErrFinished) ||
+		errors.Is(cause, ErrStopRequested) ||
+		errors.As(cause, &sigErr)
+}
+
+// watchSignals stops the app on SIGINT and SIGTERM and reloads config on SIGHUP
+// until the app context is done.
+func (app *AppCtx[T, U]) watchSignals() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	go func() {
+		// restore default handling, so that repeated signal terminates the process
+		defer signal.Stop(ch)
+
+		for {
+			var sig os.Signal
+
+			select {
+			case <-app.Done():
+				return
+			case sig = <-ch:
+			}
+
+			if sig != syscall.SIGHUP {
+				app.StopWithCause(&SignalError{Signal: sig})
+				continue
+			}
+
+			app.Log().Msg("config: reloading on SIGHUP")
+
+			err := app.Reload()
+			if err != nil {
+				app.Error(err).Msg("failed to reload config")
+			}
+		}
+	}()
+}
@@ -0,0 +1,95 @@
+package appctx
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runAndExit(app *AppCtx[struct{}, struct{}], callback func(app *AppCtx[struct{}, struct{}]) error) int {
+	code := -1
+	osExit = func(c int) {
+		code = c
+	}
+	defer func() {
+		osExit = os.Exit
+	}()
+
+	app.Run(callback)
+	app.Exit()
+
+	return code
+}
+
+func TestAppStopCause(t *testing.T) {
+	resetCommandlineFlags()
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.DisableConfig()
+	code := runAndExit(app, func(app *AppCtx[struct{}, struct{}]) error {
+		assert.NoError(t, app.StopCause())
+
+		app.StopWithCause(assert.AnError)
+		<-app.Done()
+
+		return nil
+	})
+
+	require.ErrorIs(t, app.StopCause(), assert.AnError)
+	assert.Equal(t, 1, code)
+}
+
+func TestAppStopCauseGraceful(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		cause error
+	}{
+		{"finished", nil},
+		{"stop", ErrStopRequested},
+		{"signal", &SignalError{Signal: os.Interrupt}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resetCommandlineFlags()
+
+			app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+			app.DisableConfig()
+			code := runAndExit(app, func(app *AppCtx[struct{}, struct{}]) error {
+				if tc.cause != nil {
+					app.StopWithCause(tc.cause)
+				}
+
+				return nil
+			})
+
+			if tc.cause == nil {
+				require.ErrorIs(t, app.StopCause(), ErrFinished)
+			} else {
+				require.ErrorIs(t, app.StopCause(), tc.cause)
+			}
+
+			assert.Equal(t, 0, code)
+		})
+	}
+}
+
+func TestAppStopCauseWorker(t *testing.T) {
+	resetCommandlineFlags()
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.DisableConfig()
+	code := runAndExit(app, func(app *AppCtx[struct{}, struct{}]) error {
+		app.Go("failing", func(_ *AppCtx[struct{}, struct{}]) error {
+			return assert.AnError
+		}, WithFatal())
+
+		<-app.Done()
+		return nil
+	})
+
+	var workerErr *WorkerError
+	require.ErrorAs(t, app.StopCause(), &workerErr)
+	assert.Equal(t, "failing", workerErr.Name)
+	assert.Equal(t, 1, code)
+}
@@ -0,0 +1,267 @@
+package appctx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AppCommand is a subcommand of the app, selected by its name on the command line.
+type AppCommand[T any, U any] struct {
+	name        string
+	description string
+	callback    func(ac *AppCtx[T, U]) error
+	opts        commandOptions
+	flags       []appFlag
+	commands    []*AppCommand[T, U]
+	parent      *AppCommand[T, U]
+}
+
+type commandOptions struct {
+	plugins    []string
+	hasPlugins bool
+	isDefault  bool
+}
+
+// CommandOption configures a command.
+type CommandOption func(o *commandOptions)
+
+// WithPlugins makes the command start only the listed plugins (and plugins they depend on).
+// Without this option, commands start the same plugins as their parent command, or all plugins.
+func WithPlugins(names ...string) CommandOption {
+	return func(o *commandOptions) {
+		o.plugins = names
+		o.hasPlugins = true
+	}
+}
+
+// AsDefault makes the command run when no command is specified on the command line.
+func AsDefault() CommandOption {
+	return func(o *commandOptions) {
+		o.isDefault = true
+	}
+}
+
+// Command registers a command which runs the callback instead of the one passed to Run.
+// Callback may be nil for commands which only group nested commands.
+func (app *AppCtx[T, U]) Command(name, description string, callback func(ac *AppCtx[T, U]) error, opts ...CommandOption) *AppCommand[T, U] {
+	cmd := newCommand(name, description, callback, opts)
+	app.commands = append(app.commands, cmd)
+	return cmd
+}
+
+// Command registers a nested command.
+func (cmd *AppCommand[T, U]) Command(name, description string, callback func(ac *AppCtx[T, U]) error, opts ...CommandOption) *AppCommand[T, U] {
+	sub := newCommand(name, description, callback, opts)
+	sub.parent = cmd
+	cmd.commands = append(cmd.commands, sub)
+	return sub
+}
+
+func newCommand[T any, U any](name, description string, callback func(ac *AppCtx[T, U]) error, opts []CommandOption) *AppCommand[T, U] {
+	cmd := &AppCommand[T, U]{
+		name:        name,
+		description: description,
+		callback:    callback,
+	}
+
+	for _, opt := range opts {
+		opt(&cmd.opts)
+	}
+
+	return cmd
+}
+
+// Flag registers a flag which is accepted only by this command and its nested commands.
+func (cmd *AppCommand[T, U]) Flag(name string, value, def any, description string) *AppCommand[T, U] {
+	cmd.newFlag([]string{name}, value, def, description)
+	return cmd
+}
+
+// Flag2 is like Flag, but registers both short and long names of the flag.
+func (cmd *AppCommand[T, U]) Flag2(shortName, longName string, value, def any, description string) *AppCommand[T, U] {
+	cmd.newFlag([]string{shortName, longName}, value, def, description)
+	return cmd
+}
+
+func (cmd *AppCommand[T, U]) newFlag(names []string, value any, def any, description string) {
+	cmd.flags = append(cmd.flags, appFlag{
+		names:       names,
+		description: description,
+		def:         def,
+		value:       value,
+	})
+}
+
+func (cmd *AppCommand[T, U]) path() string {
+	if cmd.parent == nil {
+		return cmd.name
+	}
+
+	return cmd.parent.path() + " " + cmd.name
+}
+
+// pluginNames returns names of plugins which the command needs, or nil if it needs all of them.
+func (cmd *AppCommand[T, U]) pluginNames() ([]string, bool) {
+	for c := cmd; c != nil; c = c.parent {
+		if c.opts.hasPlugins {
+			return c.opts.plugins, true
+		}
+	}
+
+	return nil, false
+}
+
+// CommandName returns the space-separated path of the selected command, e.g. "migrate up",
+// or an empty string if no command was selected.
+func (app *AppCtx[T, U]) CommandName() string {
+	if app.command == nil {
+		return ""
+	}
+
+	return app.command.path()
+}
+
+func defaultCommand[T any, U any](commands []*AppCommand[T, U]) *AppCommand[T, U] {
+	for _, cmd := range commands {
+		if cmd.opts.isDefault {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// selectCommand picks a command by the first argument (or the default one)
+// and parses its flags and nested commands.
+func (app *AppCtx[T, U]) selectCommand(commands []*AppCommand[T, U], args []string, inherited []appFlag) error {
+	var cmd *AppCommand[T, U]
+
+	if len(args) == 0 {
+		cmd = defaultCommand(commands)
+		if cmd == nil {
+			return nil
+		}
+	} else {
+		for _, c := range commands {
+			if c.name == args[0] {
+				cmd = c
+				break
+			}
+		}
+
+		if cmd == nil {
+			return ExitError{Code: ExitCodeFlags, Err: fmt.Errorf("unknown command \"%v\"", args[0])}
+		}
+
+		args = args[1:]
+	}
+
+	app.command = cmd
+
+	fs, err := newFlagSet(cmd.path(), inherited, cmd.flags, len(cmd.commands) == 0)
+	if err != nil {
+		return err
+	}
+
+	fs.Usage = func() {
+		app.printUsage(cmd)
+	}
+
+	err = app.parseFlags(fs, args, append(inherited, cmd.flags...))
+	if err != nil {
+		return err
+	}
+
+	if len(cmd.commands) > 0 {
+		return app.selectCommand(cmd.commands, fs.Args(), append(inherited, cmd.flags...))
+	}
+
+	app.args = fs.Args()
+	return nil
+}
+
+// commandCallback returns the callback of the selected command, or the given one if no command was selected.
+func (app *AppCtx[T, U]) commandCallback(callback func(ctx *AppCtx[T, U]) error) (func(ctx *AppCtx[T, U]) error, error) {
+	switch {
+	case app.command == nil && len(app.commands) > 0 && callback == nil:
+		return nil, ExitError{Code: ExitCodeFlags, Err: errors.New("no command specified")}
+	case app.command == nil:
+		return callback, nil
+	case app.command.callback == nil:
+		return nil, ExitError{Code: ExitCodeFlags, Err: fmt.Errorf("command \"%v\" requires a subcommand", app.command.path())}
+	default:
+		return app.command.callback, nil
+	}
+}
+
+// selectPlugins disables plugins which are not needed by the selected command.
+func (app *AppCtx[T, U]) selectPlugins() error {
+	if app.command == nil {
+		return nil
+	}
+
+	names, ok := app.command.pluginNames()
+	if !ok {
+		return nil
+	}
+
+	byName := map[string]*appPluginEntry[T, U]{}
+	for _, entry := range app.plugins {
+		byName[entry.name] = entry
+	}
+
+	needed := map[string]bool{}
+	queue := names
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		entry, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("command \"%v\" needs unknown plugin \"%v\"", app.command.path(), name)
+		}
+
+		if !needed[name] {
+			needed[name] = true
+			queue = append(queue, entry.deps...)
+		}
+	}
+
+	disabled := []string{}
+	for _, entry := range app.plugins {
+		if !needed[entry.name] {
+			entry.setState(pluginDisabled)
+			disabled = append(disabled, entry.name)
+		}
+	}
+
+	if len(disabled) > 0 {
+		app.Debug().Str("command", app.command.path()).Str("plugins", strings.Join(disabled, ", ")).Msg("disabling plugins not needed by command")
+	}
+
+	return nil
+}
+
+// getCommandHelp lists commands and their nested commands with the given indentation level.
+func getCommandHelp[T any, U any](commands []*AppCommand[T, U], level int) string {
+	if len(commands) == 0 {
+		return ""
+	}
+
+	s := ""
+	if level == 1 {
+		s += "\n\nCommands:"
+	}
+
+	for _, cmd := range commands {
+		s += "\n" + strings.Repeat("\t", level) + cmd.name + ": " + cmd.description
+		if cmd.opts.isDefault {
+			s += " (default)"
+		}
+
+		s += getCommandHelp(cmd.commands, level+1)
+	}
+
+	return s
+}
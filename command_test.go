@@ -0,0 +1,107 @@
+package appctx
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppCommands(t *testing.T) {
+	resetCommandlineFlags()
+	os.Args = append(os.Args, "-d", "migrate", "-steps", "3", "up", "-dry-run")
+
+	steps, dryRun, ran := 0, false, ""
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.Command("serve", "run the server", func(_ *AppCtx[struct{}, struct{}]) error {
+		ran = "serve"
+		return nil
+	}, AsDefault())
+
+	migrate := app.Command("migrate", "manage migrations", nil).
+		Flag("steps", &steps, 1, "number of migrations")
+	migrate.Command("up", "apply migrations", func(app *AppCtx[struct{}, struct{}]) error {
+		ran = app.CommandName()
+		return nil
+	}).Flag("dry-run", &dryRun, false, "only print migrations")
+
+	app.DisableConfig()
+	app.Run(func(_ *AppCtx[struct{}, struct{}]) error {
+		ran = "main"
+		return nil
+	})
+
+	require.False(t, app.hasError)
+	assert.Equal(t, "migrate up", ran)
+	assert.Equal(t, 3, steps)
+	assert.True(t, dryRun)
+	assert.True(t, app.cfg.Debug)
+}
+
+func TestAppDefaultCommand(t *testing.T) {
+	resetCommandlineFlags()
+
+	ran := ""
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.Command("serve", "run the server", func(app *AppCtx[struct{}, struct{}]) error {
+		ran = app.CommandName()
+		return nil
+	}, AsDefault())
+	app.Command("seed", "seed the database", func(_ *AppCtx[struct{}, struct{}]) error {
+		ran = "seed"
+		return nil
+	})
+	app.DisableConfig()
+	app.Run(nil)
+
+	require.False(t, app.hasError)
+	assert.Equal(t, "serve", ran)
+}
+
+func TestAppCommandErrors(t *testing.T) {
+	for name, args := range map[string][]string{
+		"unknown command":      {"unknown"},
+		"no command":           {},
+		"missing subcommand":   {"migrate"},
+		"unknown command flag": {"migrate", "-serve-port", "1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resetCommandlineFlags()
+			os.Args = append(os.Args, args...)
+
+			app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+			app.Command("migrate", "manage migrations", nil).
+				Command("up", "apply migrations", func(_ *AppCtx[struct{}, struct{}]) error {
+					return nil
+				})
+			app.DisableConfig()
+
+			err := app.RunE(nil)
+			require.Error(t, err)
+			assert.Equal(t, ExitCodeFlags, ExitCodeOf(err))
+		})
+	}
+}
+
+func TestAppCommandPlugins(t *testing.T) {
+	resetCommandlineFlags()
+	os.Args = append(os.Args, "migrate")
+
+	log := []string{}
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "db", log: &log})
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "http", log: &log})
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "migrations", deps: []string{"db"}, log: &log})
+	app.Command("migrate", "apply migrations", func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	}, WithPlugins("migrations"))
+	app.DisableConfig()
+	app.Run(nil)
+
+	require.False(t, app.hasError)
+	assert.Equal(t, []string{"start db", "start migrations", "stop migrations", "stop db"}, log)
+}
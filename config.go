@@ -1,28 +1,303 @@
 package appctx
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
 )
 
-func (app *AppCtx[_, _]) loadConfig(configName string) error {
-	if app.noConfig {
-		return nil
+// loadConfig fills the app config from all layers and remembers where values came from.
+func (app *AppCtx[T, U]) loadConfig() error {
+	sources, err := app.loadLayers(app.liveConfig())
+	if err != nil {
+		return err
 	}
 
-	f, err := os.Open(configName)
+	app.sources.Store(&sources)
+	return nil
+}
+
+// loadLayers applies config files, environment variables, flags and --set overrides to the set,
+// so that each of them overrides the previous ones. It returns layers which
+// the fields were set by, keyed by YAML paths.
+func (app *AppCtx[T, U]) loadLayers(set *configSet[T, U]) (map[string]ConfigSource, error) {
+	sources := map[string]ConfigSource{}
+
+	if !app.noConfig {
+		files, err := app.configPaths()
+		if err != nil {
+			return nil, fmt.Errorf("loading config: %w", err)
+		}
+
+		raw, err := app.decodeConfig(files, set)
+		if err != nil {
+			return nil, fmt.Errorf("loading config: %w", err)
+		}
+
+		_ = set.walk(func(f configField) error {
+			if hasYAMLPath(raw, f.path) {
+				sources[f.key()] = SourceFile
+			}
+
+			return nil
+		})
+	}
+
+	err := app.applyEnv(set, sources)
 	if err != nil {
-		return fmt.Errorf("opening config file \"%v\": %w", configName, err)
+		return nil, fmt.Errorf("applying environment: %w", err)
 	}
-	defer f.Close()
 
-	decoder := yaml.NewDecoder(f)
-	err = decoder.DecodeContext(app, &app.cfg)
+	app.applyFlags(set, sources)
+
+	err = app.applyOverrides(set, sources)
 	if err != nil {
-		return fmt.Errorf("decoding YAML: %w", err)
+		return nil, fmt.Errorf("applying overrides: %w", err)
 	}
 
-	return nil
+	return sources, nil
+}
+
+// configPaths returns existing config files in order they are applied:
+//   - files given by -c flags, with directories expanded to their *.yml and *.yaml files in lexical order;
+//   - files from conf.d directory next to the first config file, or inside of it if it is a directory;
+//   - environment overlays of the given files, e.g. config.production.yml.
+//
+// Missing files are an error unless AllowMissingConfig was called; overlays and conf.d are always optional.
+func (app *AppCtx[T, U]) configPaths() ([]string, error) {
+	paths := []string{}
+	files := []string{}
+	confDir := ""
+
+	for i, name := range app.configFiles {
+		info, err := os.Stat(name)
+		if i == 0 {
+			confDir = filepath.Join(filepath.Dir(name), "conf.d")
+			if err == nil && info.IsDir() {
+				confDir = filepath.Join(name, "conf.d")
+			}
+		}
+
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && app.optionalConfig:
+			files = append(files, name)
+		case err != nil:
+			return nil, fmt.Errorf("opening config file \"%v\": %w", name, err)
+		case info.IsDir():
+			entries, err := configDirFiles(name)
+			if err != nil {
+				return nil, err
+			}
+
+			paths = append(paths, entries...)
+		default:
+			files = append(files, name)
+			paths = append(paths, name)
+		}
+	}
+
+	if confDir != "" {
+		entries, err := configDirFiles(confDir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		paths = append(paths, entries...)
+	}
+
+	if app.environment != "" {
+		for _, name := range files {
+			ext := filepath.Ext(name)
+			overlay := strings.TrimSuffix(name, ext) + "." + app.environment + ext
+
+			_, err := os.Stat(overlay)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("opening config file \"%v\": %w", overlay, err)
+			}
+
+			paths = append(paths, overlay)
+		}
+	}
+
+	return paths, nil
+}
+
+// configDirFiles returns YAML files of the directory in lexical order.
+func configDirFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading config directory \"%v\": %w", dir, err)
+	}
+
+	files := []string{}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".yml" || ext == ".yaml") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	return files, nil
+}
+
+// decodeConfig merges config files and decodes the result into the app config and plugin sections. Mappings are merged
+// deeply, while other values (including lists) of later files replace earlier ones.
+// Unknown keys are reported unless strict config is disabled.
+// It also returns the merged contents as raw values.
+func (app *AppCtx[T, U]) decodeConfig(files []string, set *configSet[T, U]) (map[string]any, error) {
+	var merged ast.Node
+
+	schema, err := newConfigSchema(reflect.TypeOf(set.cfg).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, section := range set.sections {
+		err = schema.addSection(section.name, section.value.Type())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	unknown := []error{}
+
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("opening config file \"%v\": %w", name, err)
+		}
+
+		file, err := parser.ParseBytes(data, 0)
+		if err != nil {
+			return nil, fmt.Errorf("parsing config file \"%v\": %w", name, err)
+		}
+
+		for _, doc := range file.Docs {
+			if doc.Body == nil {
+				continue
+			}
+
+			body, err := interpolateYAML(doc.Body)
+			if err != nil {
+				return nil, fmt.Errorf("interpolating config file \"%v\": %w", name, err)
+			}
+
+			if !app.noStrictConfig {
+				unknown = append(unknown, schema.checkKeys(body, nil, name)...)
+			}
+
+			merged = mergeYAML(merged, body)
+		}
+	}
+
+	err = errors.Join(unknown...)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]any{}
+	if merged == nil {
+		return raw, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(nil))
+	err = decoder.DecodeFromNodeContext(app, merged, set.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("decoding YAML: %w", err)
+	}
+
+	if mapping, ok := toYAMLMapping(merged); ok {
+		for _, value := range mapping.Values {
+			for _, section := range set.sections {
+				if value.Key.GetToken().Value != section.name {
+					continue
+				}
+
+				err = decoder.DecodeFromNodeContext(app, value.Value, section.value.Addr().Interface())
+				if err != nil {
+					return nil, fmt.Errorf("decoding YAML section \"%v\": %w", section.name, err)
+				}
+			}
+		}
+	}
+
+	err = yaml.NodeToValue(merged, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("decoding YAML: %w", err)
+	}
+
+	return raw, nil
+}
+
+// mergeYAML merges overlay into base if both are mappings, otherwise overlay replaces base.
+func mergeYAML(base, overlay ast.Node) ast.Node {
+	baseMapping, ok := toYAMLMapping(base)
+	if !ok {
+		return overlay
+	}
+
+	overlayMapping, ok := toYAMLMapping(overlay)
+	if !ok {
+		return overlay
+	}
+
+	for _, value := range overlayMapping.Values {
+		key := value.Key.GetToken().Value
+
+		idx := slices.IndexFunc(baseMapping.Values, func(v *ast.MappingValueNode) bool {
+			return v.Key.GetToken().Value == key
+		})
+		if idx == -1 {
+			baseMapping.Values = append(baseMapping.Values, value)
+			continue
+		}
+
+		baseMapping.Values[idx].Value = mergeYAML(baseMapping.Values[idx].Value, value.Value)
+	}
+
+	return baseMapping
+}
+
+func toYAMLMapping(node ast.Node) (*ast.MappingNode, bool) {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n, true
+	case *ast.MappingValueNode:
+		return ast.Mapping(n.Start, false, n), true
+	default:
+		return nil, false
+	}
+}
+
+// hasYAMLPath reports whether the path exists in decoded YAML.
+func hasYAMLPath(raw map[string]any, path []string) bool {
+	for i, key := range path {
+		value, ok := raw[key]
+		if !ok {
+			return false
+		}
+
+		if i == len(path)-1 {
+			return true
+		}
+
+		raw, ok = value.(map[string]any)
+		if !ok {
+			return false
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,113 @@
+package appctx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	}
+}
+
+func TestAppConfigLayers(t *testing.T) {
+	type appConfig struct {
+		Name   string            `yaml:"name"`
+		Tags   []string          `yaml:"tags"`
+		Labels map[string]string `yaml:"labels"`
+		Extra  string            `yaml:"extra"`
+	}
+
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"config.yml": "name: base\ntags: [a, b]\nlabels:\n  team: core\n" +
+			"timeouts:\n  plugins:\n    db:\n      start: 1s\n",
+		"config.production.yml": "name: production\n",
+		"conf.d/10-tags.yml":    "tags: [c]\n",
+		"conf.d/20-labels.yaml": "labels:\n  env: prod\ntimeouts:\n  plugins:\n    db:\n      stop: 2s\n",
+		"conf.d/ignored.txt":    "name: ignored\n",
+		"extra/extra.yml":       "extra: value\n",
+	})
+
+	os.Args = []string{
+		os.Args[0], "-c", filepath.Join(dir, "config.yml"), "-c", filepath.Join(dir, "extra"), "--env", "production",
+	}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, struct{}]("Test App", "1.0.0")
+	app.Run(func(app *AppCtx[appConfig, struct{}]) error {
+		assert.Equal(t, appConfig{
+			Name:   "production",
+			Tags:   []string{"c"},
+			Labels: map[string]string{"team": "core", "env": "prod"},
+			Extra:  "value",
+		}, *app.C())
+		assert.Equal(t, map[string]appPluginTimeouts{"db": {Start: time.Second, Stop: 2 * time.Second}}, app.cfg.Timeouts.Plugins)
+		assert.Equal(t, "production", app.Environment())
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
+
+func TestAppConfigDirConfD(t *testing.T) {
+	type appConfig struct {
+		Name  string `yaml:"name"`
+		Extra string `yaml:"extra"`
+	}
+
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"conf.d/10-name.yml":        "name: parent\n",
+		"myapp/a.yml":               "name: base\nextra: base\n",
+		"myapp/conf.d/10-extra.yml": "extra: confd\n",
+	})
+
+	os.Args = []string{os.Args[0], "-c", filepath.Join(dir, "myapp")}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, struct{}]("Test App", "1.0.0")
+	app.Run(func(app *AppCtx[appConfig, struct{}]) error {
+		assert.Equal(t, appConfig{Name: "base", Extra: "confd"}, *app.C())
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
+
+func TestAppMissingConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+
+	for _, optional := range []bool{false, true} {
+		os.Args = []string{os.Args[0], "-c", configFile}
+
+		app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+		if optional {
+			app.AllowMissingConfig()
+		}
+
+		err := app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+			return nil
+		})
+
+		if optional {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err)
+			assert.Equal(t, ExitCodeConfig, ExitCodeOf(err))
+		}
+	}
+
+	resetCommandlineFlags()
+}
@@ -0,0 +1,125 @@
+package appctx
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SetEnvPrefix enables overriding config fields with environment variables named after
+// their YAML paths, e.g. field "timeouts.start" can be set by MYAPP_TIMEOUTS_START
+// if the prefix is "MYAPP". Fields with an explicit `env:"NAME"` tag are read from
+// the given variable regardless of the prefix; `env:"-"` disables the override.
+func (app *AppCtx[T, U]) SetEnvPrefix(prefix string) {
+	app.envPrefix = strings.TrimSuffix(toEnvName(prefix), "_")
+}
+
+// envName returns name of environment variable which overrides the field.
+func (app *AppCtx[T, U]) envName(f configField) (string, bool) {
+	if name, ok := f.field.Tag.Lookup("env"); ok {
+		return name, name != "-"
+	}
+
+	if app.envPrefix == "" {
+		return "", false
+	}
+
+	return app.envPrefix + "_" + toEnvName(strings.Join(f.path, "_")), true
+}
+
+// applyEnv overrides config fields with values of environment variables.
+func (app *AppCtx[T, U]) applyEnv(set *configSet[T, U], sources map[string]ConfigSource) error {
+	return set.walk(func(f configField) error {
+		name, ok := app.envName(f)
+		if !ok {
+			return nil
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+
+		err := setFromString(f.value, value)
+		if err != nil {
+			return fmt.Errorf("environment variable %v: %w", name, err)
+		}
+
+		sources[f.key()] = SourceEnv
+		return nil
+	})
+}
+
+// setFromString parses s into v. Strings are taken as is, text unmarshalers parse s themselves,
+// slices may be given as comma-separated values, everything else is parsed as YAML.
+func setFromString(v reflect.Value, s string) error {
+	switch {
+	case reflect.PointerTo(v.Type()).Implements(textUnmarshalerType):
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)) //nolint:forcetypeassert
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+		return nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 && !strings.HasPrefix(strings.TrimSpace(s), "["):
+		parts := []string{}
+		if strings.TrimSpace(s) != "" {
+			parts = strings.Split(s, ",")
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			err := setFromString(slice.Index(i), strings.TrimSpace(part))
+			if err != nil {
+				return err
+			}
+		}
+
+		v.Set(slice)
+		return nil
+	default:
+		p := reflect.New(v.Type())
+		err := yaml.Unmarshal([]byte(s), p.Interface())
+		if err != nil {
+			return err
+		}
+
+		v.Set(p.Elem())
+		return nil
+	}
+}
+
+// getEnvHelp lists environment variables which override config fields.
+func (app *AppCtx[T, U]) getEnvHelp() string {
+	s := ""
+
+	_ = app.liveConfig().walk(func(f configField) error {
+		name, ok := app.envName(f)
+		if ok {
+			s += "\n\t" + name + ": " + f.key()
+		}
+
+		return nil
+	})
+
+	if s == "" {
+		return ""
+	}
+
+	return "\n\nEnvironment variables:" + s
+}
+
+func toEnvName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
@@ -0,0 +1,76 @@
+package appctx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppEnv(t *testing.T) {
+	type appConfig struct {
+		Name    string        `yaml:"name"`
+		Port    uint16        `yaml:"port"`
+		Tags    []string      `yaml:"tags"`
+		Retries []int         `yaml:"retries"`
+		Delay   time.Duration `yaml:"delay"`
+		Token   string        `yaml:"token" env:"API_TOKEN"`
+		Ignored string        `yaml:"ignored" env:"-"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("name: file\nport: 80\ndelay: 1s\n"), 0o600))
+
+	t.Setenv("MY_APP_NAME", "env")
+	t.Setenv("MY_APP_PORT", "8080")
+	t.Setenv("MY_APP_TAGS", "a, b")
+	t.Setenv("MY_APP_RETRIES", "[1, 2]")
+	t.Setenv("MY_APP_DEBUG", "true")
+	t.Setenv("MY_APP_TIMEOUTS_START", "5s")
+	t.Setenv("MY_APP_IGNORED", "env")
+	t.Setenv("API_TOKEN", "secret")
+
+	os.Args = []string{os.Args[0], "-c", configFile, "--no-debug"}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, struct{}]("Test App", "1.0.0")
+	app.SetEnvPrefix("my-app")
+	app.Run(func(app *AppCtx[appConfig, struct{}]) error {
+		assert.Equal(t, appConfig{
+			Name:    "env",
+			Port:    8080,
+			Tags:    []string{"a", "b"},
+			Retries: []int{1, 2},
+			Delay:   time.Second,
+			Token:   "secret",
+		}, *app.C())
+		assert.Equal(t, 5*time.Second, app.cfg.Timeouts.Start)
+		assert.False(t, app.cfg.Debug)
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+	assert.Contains(t, app.getEnvHelp(), "\n\tMY_APP_PORT: port")
+	assert.Contains(t, app.getEnvHelp(), "\n\tAPI_TOKEN: token")
+	assert.NotContains(t, app.getEnvHelp(), "IGNORED")
+}
+
+func TestAppEnvError(t *testing.T) {
+	resetCommandlineFlags()
+
+	t.Setenv("APP_TIMEOUTS_START", "soon")
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.SetEnvPrefix("APP")
+	app.DisableConfig()
+
+	err := app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+	require.ErrorContains(t, err, "APP_TIMEOUTS_START")
+	assert.Equal(t, ExitCodeConfig, ExitCodeOf(err))
+}
@@ -0,0 +1,68 @@
+package appctx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Exit codes reserved by AppCtx. Apps are free to use other codes with ExitError.
+const (
+	ExitCodeOK              = 0
+	ExitCodeError           = 1
+	ExitCodeFlags           = 2
+	ExitCodeConfig          = 3
+	ExitCodePluginStart     = 4
+	ExitCodeShutdownTimeout = 5
+)
+
+// osExit is replaced in tests.
+var osExit = os.Exit
+
+// ExitCoder is implemented by errors which define the exit code of the process.
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// ExitError is an error with an associated process exit code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit code %v", e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+func (e ExitError) Unwrap() error {
+	return e.Err
+}
+
+func (e ExitError) ExitCode() int {
+	return e.Code
+}
+
+// ExitCodeOf returns exit code for an error returned by RunE: 0 for nil error,
+// code of the first ExitCoder found in the error tree, or 1 otherwise.
+func ExitCodeOf(err error) int {
+	if err == nil {
+		return ExitCodeOK
+	}
+
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+
+	return ExitCodeError
+}
+
+// Exit flushes logs and terminates the process with exit code corresponding to the result of Run.
+func (app *AppCtx[T, U]) Exit() {
+	app.flushLogs()
+	osExit(ExitCodeOf(app.err))
+}
@@ -0,0 +1,74 @@
+package appctx
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppRunE(t *testing.T) {
+	resetCommandlineFlags()
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.DisableConfig()
+	err := app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+		return ExitError{Code: 42, Err: assert.AnError}
+	})
+
+	require.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 42, ExitCodeOf(err))
+}
+
+func TestAppReservedExitCodes(t *testing.T) {
+	resetCommandlineFlags()
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.DisableConfig()
+	err := app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+	assert.Equal(t, ExitCodeOK, ExitCodeOf(err))
+
+	os.Args = []string{os.Args[0], "-c", "/nonexistent/config.yml"}
+	err = NewApp[struct{}, struct{}]("Test App", "1.0.0").RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+	assert.Equal(t, ExitCodeConfig, ExitCodeOf(err))
+
+	os.Args = []string{os.Args[0], "--unknown-flag"}
+	err = NewApp[struct{}, struct{}]("Test App", "1.0.0").RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+	assert.Equal(t, ExitCodeFlags, ExitCodeOf(err))
+
+	resetCommandlineFlags()
+
+	app = NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(&failingTestPlugin[struct{}, struct{}]{orderTestPlugin[struct{}, struct{}]{name: "failing", log: &[]string{}}})
+	app.DisableConfig()
+	err = app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+	assert.Equal(t, ExitCodePluginStart, ExitCodeOf(err))
+}
+
+func TestAppExitFlushesLogs(t *testing.T) {
+	resetCommandlineFlags()
+
+	var buf bytes.Buffer
+	w := bufio.NewWriterSize(&buf, 64*1024)
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.SetLogOutput(w)
+	app.DisableConfig()
+	code := runAndExit(app, func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+
+	assert.Equal(t, ExitCodeOK, code)
+	assert.Contains(t, buf.String(), "shutting down")
+}
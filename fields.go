@@ -0,0 +1,159 @@
+package appctx
+
+import (
+	"encoding"
+	"reflect"
+	"slices"
+	"strings"
+	"unsafe"
+
+	"github.com/goccy/go-yaml"
+)
+
+// configField is a single configurable value of the app config.
+type configField struct {
+	root  int
+	path  []string
+	index []int
+	field reflect.StructField
+	value reflect.Value
+}
+
+// key returns dotted YAML path of the field, e.g. "timeouts.start".
+func (f configField) key() string {
+	return strings.Join(f.path, ".")
+}
+
+var (
+	yamlBytesUnmarshalerType     = reflect.TypeFor[yaml.BytesUnmarshaler]()
+	yamlInterfaceUnmarshalerType = reflect.TypeFor[yaml.InterfaceUnmarshaler]()
+	textUnmarshalerType          = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// isConfigLeaf reports whether values of the type are decoded as a whole rather than field by field.
+func isConfigLeaf(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+
+	pt := reflect.PointerTo(t)
+	return pt.Implements(yamlBytesUnmarshalerType) ||
+		pt.Implements(yamlInterfaceUnmarshalerType) ||
+		pt.Implements(textUnmarshalerType)
+}
+
+// walkConfig calls fn for each leaf value of the struct pointed to by ptr, following
+// the same rules as the YAML decoder: unexported and "-" fields are skipped,
+// inline fields do not add a path segment.
+func walkConfig(ptr any, fn func(f configField) error) error {
+	return walkConfigStruct(reflect.ValueOf(ptr).Elem(), nil, nil, fn)
+}
+
+func walkConfigStruct(v reflect.Value, path []string, index []int, fn func(f configField) error) error {
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if (!field.IsExported() && !field.Anonymous) || yamlTag(field) == "-" {
+			continue
+		}
+
+		name, inline := yamlFieldName(field)
+		fieldPath := path
+		if !inline {
+			fieldPath = append(slices.Clip(path), name)
+		}
+
+		fieldIndex := append(slices.Clip(index), i)
+		fieldValue := settable(v.Field(i))
+
+		if !isConfigLeaf(field.Type) {
+			err := walkConfigStruct(fieldValue, fieldPath, fieldIndex, fn)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		err := fn(configField{
+			path:  fieldPath,
+			index: fieldIndex,
+			field: field,
+			value: fieldValue,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func yamlTag(field reflect.StructField) string {
+	tag, ok := field.Tag.Lookup("yaml")
+	if !ok {
+		tag = field.Tag.Get("json")
+	}
+
+	return tag
+}
+
+// yamlFieldName returns YAML key of the field the same way YAML decoder does.
+func yamlFieldName(field reflect.StructField) (name string, inline bool) {
+	options := strings.Split(yamlTag(field), ",")
+
+	name = options[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+
+	for _, opt := range options[1:] {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+
+	return name, inline
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but returns settable values
+// even for fields promoted through unexported embedded structs.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		v = settable(v.Field(i))
+	}
+
+	return v
+}
+
+// settable returns a settable view of an addressable value.
+func settable(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem() //nolint:gosec
+}
+
+// findStruct looks for a struct of type typ located at address addr inside of the struct v
+// (including v itself) and returns its settable value.
+func findStruct(v reflect.Value, typ reflect.Type, addr uintptr) (reflect.Value, []int, bool) {
+	if v.Type() == typ && v.UnsafeAddr() == addr {
+		return settable(v), nil, true
+	}
+
+	for i := range v.NumField() {
+		field := v.Field(i)
+		if field.Kind() != reflect.Struct {
+			continue
+		}
+
+		found, index, ok := findStruct(field, typ, addr)
+		if ok {
+			return found, append([]int{i}, index...), true
+		}
+	}
+
+	return reflect.Value{}, nil, false
+}
@@ -1,9 +1,12 @@
 package appctx
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -12,6 +15,21 @@ type appFlag struct {
 	description string
 	def         any
 	value       any
+	group       string
+}
+
+// appFlagValue is a value given to a flag on the command line.
+type appFlagValue struct {
+	flag  appFlag
+	value reflect.Value
+}
+
+// appPluginFlagger is implemented by plugins which accept command-line flags.
+// Flags registered by the hook are prefixed with the plugin name, e.g. "httpserver.port",
+// and take precedence over values from the config file.
+type appPluginFlagger[T any, U any] interface {
+	AppPlugin[T, U]
+	PluginFlags(ac *AppCtx[T, U])
 }
 
 func (app *AppCtx[_, _]) Flag(name string, value, def any, description string) {
@@ -23,70 +41,237 @@ func (app *AppCtx[_, _]) Flag2(shortName, longName string, value, def any, descr
 }
 
 func (app *AppCtx[_, _]) newFlag(names []string, value any, def any, description string) {
+	if app.flagGroup != "" {
+		prefixed := make([]string, 0, len(names))
+		for _, name := range names {
+			prefixed = append(prefixed, app.flagGroup+"."+name)
+		}
+
+		names = prefixed
+	}
+
 	app.flags = append(app.flags, appFlag{
 		names:       names,
 		description: description,
 		def:         def,
 		value:       value,
+		group:       app.flagGroup,
 	})
 }
 
-func (app *AppCtx[_, _]) initFlags() error {
+// registerPluginFlags lets plugins register their flags.
+func (app *AppCtx[T, U]) registerPluginFlags() error {
+	defer func() {
+		app.flagGroup = ""
+	}()
+
+	for _, entry := range app.plugins {
+		plugin, ok := entry.plugin.(appPluginFlagger[T, U])
+		if !ok {
+			continue
+		}
+
+		app.flagGroup = entry.name
+
+		err := app.callRecovering(func(ac *AppCtx[T, U]) error {
+			plugin.PluginFlags(ac)
+			return nil
+		})
+		if err != nil {
+			app.logPanic(err, "plugin", entry.name)
+			return &PluginError{Op: "registering flags of", Name: entry.name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (app *AppCtx[T, U]) initFlags() error {
 	if app.noFlags {
+		app.command = defaultCommand(app.commands)
 		return nil
 	}
 
-	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs, err := newFlagSet(os.Args[0], nil, app.flags, len(app.commands) == 0)
+	if err != nil {
+		return err
+	}
 
-	for _, f := range app.flags {
-		for _, name := range f.names {
-			switch v := f.value.(type) {
-			case *string:
-				def, ok := f.def.(string)
-				if !ok {
-					return fmt.Errorf("invalid default value type of flag %v: %T (should be %T)", name, f.def, v)
-				}
-
-				fs.StringVar(v, name, def, "")
-			case *int:
-				def, ok := f.def.(int)
-				if !ok {
-					return fmt.Errorf("invalid default value type of flag %v: %T (should be %T)", name, f.def, v)
-				}
-
-				fs.IntVar(v, name, def, "")
-			case *bool:
-				def, ok := f.def.(bool)
-				if !ok {
-					return fmt.Errorf("invalid default value type of flag %v: %T (should be %T)", name, f.def, v)
-				}
-
-				fs.BoolVar(v, name, def, "")
-			}
+	fs.Usage = func() {
+		app.printUsage(nil)
+	}
+
+	err = app.parseFlags(fs, os.Args[1:], app.flags)
+	if err != nil {
+		return err
+	}
+
+	if len(app.commands) > 0 {
+		return app.selectCommand(app.commands, fs.Args(), app.flags)
+	}
+
+	app.args = fs.Args()
+	return nil
+}
+
+// Args returns positional arguments left after parsing flags and selecting a command.
+func (app *AppCtx[T, U]) Args() []string {
+	return app.args
+}
+
+// newFlagSet creates a flag set with the given flags. Inherited flags (i.e. flags of parent commands)
+// are registered with their current values as defaults, so that values parsed earlier are kept.
+func newFlagSet(name string, inherited, flags []appFlag, interspersed bool) (*flagSet, error) {
+	fs := newFlagSetParser(name, interspersed)
+
+	for _, f := range inherited {
+		err := registerFlag(fs, f, reflect.ValueOf(f.value).Elem().Interface())
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	fs.Usage = func() {
-		fmt.Println(app.title + " v" + app.version + "\n" +
-			"Usage:\n" +
-			app.getFlagHelp())
+	for _, f := range flags {
+		err := registerFlag(fs, f, f.def)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	err := fs.Parse(os.Args[1:])
+	return fs, nil
+}
+
+func registerFlag(fs *flagSet, f appFlag, def any) error {
+	value, err := newFlagValue(f.value, def)
 	if err != nil {
-		return fmt.Errorf("parsing flags: %w", err)
+		return fmt.Errorf("flag %v: %w", formatFlagName(f.names[len(f.names)-1]), err)
+	}
+
+	for _, name := range f.names {
+		err = fs.Var(value, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseFlags parses args and remembers values of flags which were set explicitly.
+func (app *AppCtx[T, U]) parseFlags(fs *flagSet, args []string, flags []appFlag) error {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitError{Code: ExitCodeOK, Err: err}
+	} else if err != nil {
+		return ExitError{Code: ExitCodeFlags, Err: fmt.Errorf("parsing flags: %w", err)}
+	}
+
+	for _, f := range flags {
+		if !slices.ContainsFunc(f.names, fs.Visited) {
+			continue
+		}
+
+		v := reflect.ValueOf(f.value).Elem()
+		value := reflect.New(v.Type()).Elem()
+		value.Set(v)
+
+		app.flagValues = append(app.flagValues, appFlagValue{flag: f, value: value})
 	}
 
 	return nil
 }
 
-func (app *AppCtx[_, _]) getFlagHelp() string {
+// applyFlags sets values given on the command line once again, so that they override
+// values from the config file. Flags bound to config fields are applied
+// to the same fields of the set.
+func (app *AppCtx[T, U]) applyFlags(set *configSet[T, U], sources map[string]ConfigSource) {
+	live := app.liveConfig()
+
+	for _, fv := range app.flagValues {
+		target := reflect.ValueOf(fv.flag.value).Elem()
+
+		f, ok := live.find(fv.flag.value)
+		if ok {
+			target = set.field(f)
+			sources[f.key()] = SourceFlag
+		}
+
+		target.Set(fv.value)
+	}
+}
+
+// registerTagFlags registers flags for config fields with `flag:"name,short,usage"` tags.
+// Name defaults to the YAML path of the field, short name and usage are optional.
+func (app *AppCtx[T, U]) registerTagFlags() error {
+	return app.liveConfig().walk(func(f configField) error {
+		tag, ok := f.field.Tag.Lookup("flag")
+		if !ok || tag == "-" {
+			return nil
+		}
+
+		parts := strings.SplitN(tag, ",", 3)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+
+		name, short, usage := parts[0], parts[1], parts[2]
+		if name == "" {
+			name = strings.ReplaceAll(f.key(), "_", "-")
+		}
+
+		if len(short) > 1 {
+			return fmt.Errorf("field %v: short flag name \"%v\" must be a single character", f.key(), short)
+		}
+
+		names := []string{name}
+		if short != "" {
+			names = []string{short, name}
+		}
+
+		// nil default keeps the value set so far, e.g. by plugins
+		app.newFlag(names, f.value.Addr().Interface(), nil, usage)
+		return nil
+	})
+}
+
+// printUsage prints help for the app, or for the command if it is not nil.
+func (app *AppCtx[T, U]) printUsage(cmd *AppCommand[T, U]) {
+	flags, commands, usage, env := app.flags, app.commands, "Usage:\n", app.getEnvHelp()
+	if cmd != nil {
+		flags, commands, usage, env = cmd.flags, cmd.commands, "Usage of "+cmd.path()+":\n", ""
+	}
+
+	fmt.Println(app.title + " v" + app.version + "\n" +
+		usage +
+		getFlagHelp(flags) +
+		getCommandHelp(commands, 1) +
+		env)
+}
+
+func getFlagHelp(flags []appFlag) string {
+	// ungrouped flags go first, so that flags registered after plugin ones
+	// do not end up under a header of a plugin
+	ordered := make([]appFlag, 0, len(flags))
+	for _, grouped := range []bool{false, true} {
+		for _, f := range flags {
+			if (f.group != "") == grouped {
+				ordered = append(ordered, f)
+			}
+		}
+	}
+
 	s := ""
+	group := ""
+
+	for _, f := range ordered {
+		if f.group != group {
+			group = f.group
+			s += "\n\n" + group + " flags:"
+		}
 
-	for _, f := range app.flags {
 		prefixedNames := []string{}
 		for _, name := range f.names {
-			prefixedNames = append(prefixedNames, map[bool]string{false: "-", true: "--"}[len(name) > 1]+name)
+			prefixedNames = append(prefixedNames, formatFlagName(name))
 		}
 
 		s += "\n\t" + strings.Join(prefixedNames, ", ") + ": " + f.description
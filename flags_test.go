@@ -0,0 +1,168 @@
+package appctx
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flagsTestPlugin[T any, U any] struct {
+	Port  int    `yaml:"port"`
+	Token string `yaml:"token"`
+}
+
+func (pl *flagsTestPlugin[T, U]) PluginName() string {
+	return "server"
+}
+
+func (pl *flagsTestPlugin[T, U]) PluginFlags(ac *AppCtx[T, U]) {
+	ac.Flag("port", &pl.Port, 80, "port to listen on")
+	ac.Flag("token", &pl.Token, "", "access token")
+}
+
+func (pl *flagsTestPlugin[T, U]) PluginReconfigure(_ *AppCtx[T, U], _, _ AppPlugin[T, U]) error {
+	return nil
+}
+
+func TestPluginFlags(t *testing.T) {
+	type appPlugins struct {
+		Server flagsTestPlugin[struct{}, appPlugins] `yaml:",inline"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("port: 8080\ntoken: file\n"), 0o600))
+
+	os.Args = []string{os.Args[0], "-c", configFile, "-server.port", "9090"}
+	defer resetCommandlineFlags()
+
+	app := NewApp[struct{}, appPlugins]("Test App", "1.0.0")
+	app.RegisterPlugin(&app.P().Server)
+	app.Run(func(app *AppCtx[struct{}, appPlugins]) error {
+		assert.Equal(t, 9090, app.P().Server.Port)
+		assert.Equal(t, "file", app.P().Server.Token)
+
+		require.NoError(t, os.WriteFile(configFile, []byte("port: 8081\ntoken: reloaded\n"), 0o600))
+		require.NoError(t, app.Reload())
+		assert.Equal(t, 9090, app.P().Server.Port)
+		assert.Equal(t, "reloaded", app.P().Server.Token)
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
+
+func TestPluginFlagsHelp(t *testing.T) {
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.Flag("verbose", new(bool), false, "verbose output")
+	app.flagGroup = "server"
+	app.Flag("port", new(int), 80, "port to listen on")
+	app.flagGroup = ""
+
+	assert.Equal(t, "\n\t--verbose: verbose output\n\nserver flags:\n\t--server.port: port to listen on", getFlagHelp(app.flags))
+}
+
+func TestTagFlagsHelp(t *testing.T) {
+	type appConfig struct {
+		Name string `yaml:"name" flag:"name,n,app name"`
+	}
+
+	type appPlugins struct {
+		Server flagsTestPlugin[appConfig, appPlugins] `yaml:",inline"`
+	}
+
+	os.Args = []string{os.Args[0], "--help"}
+	defer resetCommandlineFlags()
+
+	out := captureStdout(t, func() {
+		app := NewApp[appConfig, appPlugins]("Test App", "1.0.0")
+		app.RegisterPlugin(&app.P().Server)
+		app.DisableConfig()
+		app.Run(func(_ *AppCtx[appConfig, appPlugins]) error {
+			return nil
+		})
+	})
+
+	assert.NotContains(t, out, "\n flags:")
+	assert.Contains(t, out, "\n\t-n, --name: app name\n\nserver flags:\n\t--server.port: port to listen on")
+}
+
+func TestFlagTypes(t *testing.T) {
+	resetCommandlineFlags()
+	os.Args = append(os.Args,
+		"-timeout", "1m30s", "-ratio", "0.5", "-size", "1099511627776", "-workers", "4", "-port", "8080",
+		"-tag", "a", "-tag", "b", "-label", "env=prod", "-label", "team=core", "-ip", "127.0.0.1",
+	)
+
+	var (
+		timeout time.Duration
+		ratio   float64
+		size    int64
+		workers uint
+		port    uint16
+		tags    []string
+		labels  map[string]string
+		ip      net.IP
+		level   zerolog.Level
+	)
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.Flag("timeout", &timeout, time.Second, "")
+	app.Flag("ratio", &ratio, 1.0, "")
+	app.Flag("size", &size, int64(0), "")
+	app.Flag("workers", &workers, uint(1), "")
+	app.Flag("port", &port, uint16(80), "")
+	app.Flag("tag", &tags, []string{"default"}, "")
+	app.Flag("label", &labels, map[string]string{"env": "dev"}, "")
+	app.Flag("ip", &ip, nil, "")
+	app.Flag("level", &level, zerolog.WarnLevel, "")
+	app.DisableConfig()
+	app.Run(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+
+	require.False(t, app.hasError)
+	assert.Equal(t, 90*time.Second, timeout)
+	assert.InDelta(t, 0.5, ratio, 0)
+	assert.Equal(t, int64(1)<<40, size)
+	assert.Equal(t, uint(4), workers)
+	assert.Equal(t, uint16(8080), port)
+	assert.Equal(t, []string{"a", "b"}, tags)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "core"}, labels)
+	assert.Equal(t, "127.0.0.1", ip.String())
+	assert.Equal(t, zerolog.WarnLevel, level)
+}
+
+func TestFlagErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		value, def any
+		args       []string
+	}{
+		"unsupported type":   {value: new(complex64), def: complex64(0)},
+		"invalid default":    {value: new(time.Duration), def: "1s"},
+		"uint16 overflow":    {value: new(uint16), def: uint16(0), args: []string{"-value", "65536"}},
+		"invalid key=value":  {value: new(map[string]string), def: nil, args: []string{"-value", "novalue"}},
+		"invalid duration":   {value: new(time.Duration), def: time.Duration(0), args: []string{"-value", "1x"}},
+		"invalid text value": {value: new(net.IP), def: nil, args: []string{"-value", "localhost"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resetCommandlineFlags()
+			os.Args = append(os.Args, tc.args...)
+
+			app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+			app.Flag("value", tc.value, tc.def, "")
+			app.DisableConfig()
+
+			err := app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+				return nil
+			})
+			require.Error(t, err)
+		})
+	}
+}
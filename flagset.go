@@ -0,0 +1,202 @@
+package appctx
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// flagSet parses command-line arguments in GNU style: "--name=value", "--name value",
+// bundled short flags ("-vd", "-cfile"), "--no-name" for boolean flags and "--" terminator.
+// For compatibility, long flags may also be given with a single dash, e.g. "-debug".
+type flagSet struct {
+	name  string
+	flags map[string]flag.Value
+	set   map[string]bool
+	args  []string
+	Usage func()
+
+	// interspersed allows flags after positional arguments. It is disabled for sets
+	// which select commands, so that the rest of arguments is left to the command.
+	interspersed bool
+}
+
+func newFlagSetParser(name string, interspersed bool) *flagSet {
+	return &flagSet{
+		name:         name,
+		flags:        map[string]flag.Value{},
+		set:          map[string]bool{},
+		interspersed: interspersed,
+	}
+}
+
+func (fs *flagSet) Var(value flag.Value, name string) error {
+	if _, ok := fs.flags[name]; ok {
+		return fmt.Errorf("flag %v is defined more than once", formatFlagName(name))
+	}
+
+	fs.flags[name] = value
+	return nil
+}
+
+// Args returns positional arguments which were not consumed by flags.
+func (fs *flagSet) Args() []string {
+	return fs.args
+}
+
+// Visited reports whether the flag was given on the command line.
+func (fs *flagSet) Visited(name string) bool {
+	return fs.set[name]
+}
+
+func (fs *flagSet) Parse(args []string) error {
+	err := fs.parse(args)
+	if err != nil && fs.Usage != nil {
+		fs.Usage()
+	}
+
+	return err
+}
+
+func (fs *flagSet) parse(args []string) error {
+	fs.args = nil
+
+	for len(args) > 0 {
+		arg := args[0]
+		args = args[1:]
+
+		switch {
+		case arg == "--":
+			fs.args = append(fs.args, args...)
+			return nil
+		case strings.HasPrefix(arg, "--"):
+			var err error
+			args, err = fs.parseLong(arg[2:], args)
+			if err != nil {
+				return err
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			var err error
+
+			// "-name" and "-name=value" are accepted for registered long flags
+			name, _, _ := strings.Cut(arg[1:], "=")
+			if _, ok := fs.flags[name]; ok && len(name) > 1 || name == "help" {
+				args, err = fs.parseLong(arg[1:], args)
+			} else {
+				args, err = fs.parseShort(arg[1:], args)
+			}
+
+			if err != nil {
+				return err
+			}
+		case fs.interspersed:
+			fs.args = append(fs.args, arg)
+		default:
+			fs.args = append(fs.args, arg)
+			fs.args = append(fs.args, args...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// parseLong parses a long flag (without leading dashes) and returns the remaining arguments.
+func (fs *flagSet) parseLong(arg string, args []string) ([]string, error) {
+	name, value, hasValue := strings.Cut(arg, "=")
+
+	flagValue, ok := fs.flags[name]
+	if !ok {
+		negated, isNegation := strings.CutPrefix(name, "no-")
+		if flagValue, ok = fs.flags[negated]; isNegation && ok && isBoolFlag(flagValue) {
+			if hasValue {
+				return nil, fmt.Errorf("flag %v does not take a value", formatFlagName(name))
+			}
+
+			return args, fs.setFlag(negated, flagValue, "false")
+		}
+
+		if name == "help" || name == "h" {
+			return nil, flag.ErrHelp
+		}
+
+		return nil, fmt.Errorf("unknown flag %v", formatFlagName(name))
+	}
+
+	switch {
+	case hasValue:
+	case isBoolFlag(flagValue):
+		value = "true"
+	case len(args) > 0:
+		value, args = args[0], args[1:]
+	default:
+		return nil, fmt.Errorf("flag %v requires a value", formatFlagName(name))
+	}
+
+	return args, fs.setFlag(name, flagValue, value)
+}
+
+// parseShort parses bundled short flags (without leading dash) and returns the remaining arguments.
+// The first flag which is not boolean consumes the rest of the bundle or the next argument as its value.
+func (fs *flagSet) parseShort(arg string, args []string) ([]string, error) {
+	for i := 0; i < len(arg); i++ {
+		name := arg[i : i+1]
+
+		flagValue, ok := fs.flags[name]
+		if !ok {
+			if name == "h" {
+				return nil, flag.ErrHelp
+			}
+
+			return nil, fmt.Errorf("unknown flag %v", formatFlagName(name))
+		}
+
+		rest := arg[i+1:]
+		if isBoolFlag(flagValue) && !strings.HasPrefix(rest, "=") {
+			err := fs.setFlag(name, flagValue, "true")
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		var value string
+		switch {
+		case rest != "":
+			value = strings.TrimPrefix(rest, "=")
+		case len(args) > 0:
+			value, args = args[0], args[1:]
+		default:
+			return nil, fmt.Errorf("flag %v requires a value", formatFlagName(name))
+		}
+
+		return args, fs.setFlag(name, flagValue, value)
+	}
+
+	return args, nil
+}
+
+func (fs *flagSet) setFlag(name string, value flag.Value, s string) error {
+	err := value.Set(s)
+	if err != nil {
+		return fmt.Errorf("invalid value \"%v\" for flag %v: %w", s, formatFlagName(name), err)
+	}
+
+	fs.set[name] = true
+	return nil
+}
+
+func isBoolFlag(value flag.Value) bool {
+	b, ok := value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// formatFlagName prefixes the flag name with dashes the way it is shown in help.
+func formatFlagName(name string) string {
+	if len(name) > 1 {
+		return "--" + name
+	}
+
+	return "-" + name
+}
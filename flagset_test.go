@@ -0,0 +1,106 @@
+package appctx
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagSetParse(t *testing.T) {
+	type result struct {
+		Verbose bool
+		Debug   bool
+		Config  string
+		Level   int
+		Args    []string
+	}
+
+	for name, tc := range map[string]struct {
+		args []string
+		want result
+	}{
+		"long with value":      {[]string{"--config=app.yml", "--level", "3"}, result{Config: "app.yml", Level: 3}},
+		"long with one dash":   {[]string{"-config", "app.yml", "-level=3"}, result{Config: "app.yml", Level: 3}},
+		"bundled short":        {[]string{"-vd"}, result{Verbose: true, Debug: true}},
+		"bundled with value":   {[]string{"-vdc", "app.yml"}, result{Verbose: true, Debug: true, Config: "app.yml"}},
+		"short attached value": {[]string{"-capp.yml", "-l=2"}, result{Config: "app.yml", Level: 2}},
+		"negation":             {[]string{"--verbose", "--no-verbose"}, result{}},
+		"explicit bool value":  {[]string{"--debug=true", "-v"}, result{Debug: true, Verbose: true}},
+		"terminator":           {[]string{"-v", "--", "-d", "file"}, result{Verbose: true, Args: []string{"-d", "file"}}},
+		"interspersed":         {[]string{"a", "-v", "b", "-"}, result{Verbose: true, Args: []string{"a", "b", "-"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var got result
+
+			fs, err := newFlagSet("test", nil, []appFlag{
+				{names: []string{"v", "verbose"}, value: &got.Verbose, def: false},
+				{names: []string{"d", "debug"}, value: &got.Debug, def: false},
+				{names: []string{"c", "config"}, value: &got.Config, def: ""},
+				{names: []string{"l", "level"}, value: &got.Level, def: 0},
+			}, true)
+			require.NoError(t, err)
+
+			require.NoError(t, fs.Parse(tc.args))
+			got.Args = fs.Args()
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFlagSetErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		args []string
+		err  error
+	}{
+		"unknown long":      {args: []string{"--unknown"}},
+		"unknown short":     {args: []string{"-vx"}},
+		"missing value":     {args: []string{"--level"}},
+		"invalid value":     {args: []string{"--level=high"}},
+		"negated non-bool":  {args: []string{"--no-level"}},
+		"negation value":    {args: []string{"--no-verbose=true"}},
+		"help":              {args: []string{"--help"}, err: flag.ErrHelp},
+		"short help":        {args: []string{"-h"}, err: flag.ErrHelp},
+		"single dash help":  {args: []string{"-help"}, err: flag.ErrHelp},
+		"help after values": {args: []string{"-v", "--level", "1", "-h"}, err: flag.ErrHelp},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var verbose bool
+			var level int
+
+			fs, err := newFlagSet("test", nil, []appFlag{
+				{names: []string{"v", "verbose"}, value: &verbose, def: false},
+				{names: []string{"level"}, value: &level, def: 0},
+			}, true)
+			require.NoError(t, err)
+
+			err = fs.Parse(tc.args)
+			require.Error(t, err)
+
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestAppArgs(t *testing.T) {
+	resetCommandlineFlags()
+	os.Args = append(os.Args, "-d", "import", "--dry-run", "a.csv", "--", "-b.csv")
+
+	dryRun := false
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.Command("import", "import files", func(app *AppCtx[struct{}, struct{}]) error {
+		assert.Equal(t, []string{"a.csv", "-b.csv"}, app.Args())
+		return nil
+	}).Flag("dry-run", &dryRun, false, "")
+	app.DisableConfig()
+	app.Run(nil)
+
+	require.False(t, app.hasError)
+	assert.True(t, dryRun)
+	assert.True(t, app.cfg.Debug)
+}
@@ -0,0 +1,203 @@
+package appctx
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flagValue adapts a variable of a basic type to flag.Value.
+type flagValue[V any] struct {
+	p      *V
+	parse  func(s string) (V, error)
+	format func(v V) string
+}
+
+func (v *flagValue[V]) Set(s string) error {
+	parsed, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+
+	*v.p = parsed
+	return nil
+}
+
+func (v *flagValue[V]) String() string {
+	if v.p == nil {
+		return ""
+	}
+
+	return v.format(*v.p)
+}
+
+type boolFlagValue struct {
+	flagValue[bool]
+}
+
+func (v *boolFlagValue) IsBoolFlag() bool {
+	return true
+}
+
+// sliceFlagValue collects values of a repeatable flag. The first value given
+// on the command line replaces the default ones.
+type sliceFlagValue struct {
+	p   *[]string
+	set bool
+}
+
+func (v *sliceFlagValue) Set(s string) error {
+	if !v.set {
+		*v.p = nil
+		v.set = true
+	}
+
+	*v.p = append(*v.p, s)
+	return nil
+}
+
+func (v *sliceFlagValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+
+	return strings.Join(*v.p, ",")
+}
+
+// mapFlagValue collects key=value pairs of a repeatable flag. The first pair given
+// on the command line replaces the default ones.
+type mapFlagValue struct {
+	p   *map[string]string
+	set bool
+}
+
+func (v *mapFlagValue) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return errors.New("expected key=value")
+	}
+
+	if !v.set {
+		*v.p = map[string]string{}
+		v.set = true
+	}
+
+	(*v.p)[key] = value
+	return nil
+}
+
+func (v *mapFlagValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+
+	pairs := []string{}
+	for key, value := range *v.p {
+		pairs = append(pairs, key+"="+value)
+	}
+	slices.Sort(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// textFlagValue adapts encoding.TextUnmarshaler to flag.Value.
+type textFlagValue struct {
+	u encoding.TextUnmarshaler
+}
+
+func (v *textFlagValue) Set(s string) error {
+	return v.u.UnmarshalText([]byte(s))
+}
+
+func (v *textFlagValue) String() string {
+	m, ok := v.u.(encoding.TextMarshaler)
+	if !ok || reflect.ValueOf(m).IsNil() {
+		return ""
+	}
+
+	text, err := m.MarshalText()
+	if err != nil {
+		return ""
+	}
+
+	return string(text)
+}
+
+func parseInt[V ~int | ~int64](bits int) func(s string) (V, error) {
+	return func(s string) (V, error) {
+		v, err := strconv.ParseInt(s, 0, bits)
+		return V(v), err
+	}
+}
+
+func parseUint[V ~uint | ~uint16](bits int) func(s string) (V, error) {
+	return func(s string) (V, error) {
+		v, err := strconv.ParseUint(s, 0, bits)
+		return V(v), err
+	}
+}
+
+func formatInt[V ~int | ~int64](v V) string {
+	return strconv.FormatInt(int64(v), 10)
+}
+
+func formatUint[V ~uint | ~uint16](v V) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+// newFlagValue sets the variable pointed to by ptr to def (unless def is nil)
+// and returns flag.Value which parses command-line values into the variable.
+func newFlagValue(ptr any, def any) (flag.Value, error) {
+	pv := reflect.ValueOf(ptr)
+	if pv.Kind() != reflect.Pointer || pv.IsNil() {
+		return nil, fmt.Errorf("value must be a non-nil pointer, got %T", ptr)
+	}
+
+	if def != nil {
+		dv := reflect.ValueOf(def)
+		if !dv.Type().AssignableTo(pv.Type().Elem()) {
+			return nil, fmt.Errorf("invalid default value type: %T (should be %v)", def, pv.Type().Elem())
+		}
+
+		pv.Elem().Set(dv)
+	}
+
+	switch p := ptr.(type) {
+	case flag.Value:
+		return p, nil
+	case *string:
+		return &flagValue[string]{p, func(s string) (string, error) { return s, nil }, func(v string) string { return v }}, nil
+	case *bool:
+		return &boolFlagValue{flagValue[bool]{p, strconv.ParseBool, strconv.FormatBool}}, nil
+	case *int:
+		return &flagValue[int]{p, parseInt[int](strconv.IntSize), formatInt[int]}, nil
+	case *int64:
+		return &flagValue[int64]{p, parseInt[int64](64), formatInt[int64]}, nil
+	case *uint:
+		return &flagValue[uint]{p, parseUint[uint](strconv.IntSize), formatUint[uint]}, nil
+	case *uint16:
+		return &flagValue[uint16]{p, parseUint[uint16](16), formatUint[uint16]}, nil
+	case *float64:
+		return &flagValue[float64]{
+			p,
+			func(s string) (float64, error) { return strconv.ParseFloat(s, 64) },
+			func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) },
+		}, nil
+	case *time.Duration:
+		return &flagValue[time.Duration]{p, time.ParseDuration, time.Duration.String}, nil
+	case *[]string:
+		return &sliceFlagValue{p: p}, nil
+	case *map[string]string:
+		return &mapFlagValue{p: p}, nil
+	case encoding.TextUnmarshaler:
+		return &textFlagValue{p}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", ptr)
+	}
+}
@@ -0,0 +1,187 @@
+package appctx
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultHealthTimeout = 5 * time.Second
+
+type appPluginHealthChecker[T any, U any] interface {
+	AppPlugin[T, U]
+	PluginHealth(ac *AppCtx[T, U]) error
+}
+
+type appHealthCfg struct {
+	Timeout  time.Duration `yaml:"timeout"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// HealthReport is a result of running health checks of all plugins.
+type HealthReport struct {
+	Healthy   bool                          `json:"healthy"`
+	CheckedAt time.Time                     `json:"checked_at"`
+	Plugins   map[string]PluginHealthReport `json:"plugins"`
+}
+
+// PluginHealthReport is a result of a health check of a single plugin.
+// Plugins which are not running are reported as unhealthy.
+type PluginHealthReport struct {
+	Healthy bool          `json:"healthy"`
+	State   string        `json:"state"`
+	Error   string        `json:"error,omitempty"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+type appHealth struct {
+	mu         sync.Mutex
+	last       *HealthReport
+	background *HealthReport
+}
+
+// SetHealthChecks sets timeout of a single plugin health check and interval of
+// periodic background checks. Zero interval disables background checks.
+// Values from the "health" section of the config file take precedence.
+func (app *AppCtx[_, _]) SetHealthChecks(timeout, interval time.Duration) {
+	app.cfg.Health.Timeout = timeout
+	app.cfg.Health.Interval = interval
+}
+
+// Health runs health checks of all plugins concurrently and returns the aggregated report.
+func (app *AppCtx[T, U]) Health() HealthReport {
+	report := HealthReport{
+		Healthy:   true,
+		CheckedAt: time.Now(),
+		Plugins:   make(map[string]PluginHealthReport, len(app.plugins)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, entry := range app.plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			res := app.checkPluginHealth(entry)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Plugins[entry.name] = res
+			report.Healthy = report.Healthy && res.Healthy
+		}()
+	}
+
+	wg.Wait()
+
+	app.health.mu.Lock()
+	app.health.last = &report
+	app.health.mu.Unlock()
+
+	return report
+}
+
+// LastHealth returns the most recent report produced by Health or by background checks.
+func (app *AppCtx[T, U]) LastHealth() (HealthReport, bool) {
+	app.health.mu.Lock()
+	defer app.health.mu.Unlock()
+
+	if app.health.last == nil {
+		return HealthReport{}, false
+	}
+
+	return *app.health.last, true
+}
+
+// BackgroundHealth returns the most recent report produced by periodic background checks.
+// Unlike LastHealth, it is not affected by on-demand Health calls, so it reflects
+// the state of the app as of the last check interval at most.
+// It returns false if background checks are disabled or have not run yet.
+func (app *AppCtx[T, U]) BackgroundHealth() (HealthReport, bool) {
+	app.health.mu.Lock()
+	defer app.health.mu.Unlock()
+
+	if app.health.background == nil {
+		return HealthReport{}, false
+	}
+
+	return *app.health.background, true
+}
+
+func (app *AppCtx[T, U]) checkPluginHealth(entry *appPluginEntry[T, U]) PluginHealthReport {
+	state := entry.getState()
+	res := PluginHealthReport{
+		Healthy: state == pluginStarted || state == pluginDisabled,
+		State:   state.String(),
+	}
+
+	checker, ok := entry.plugin.(appPluginHealthChecker[T, U])
+	if !ok || state != pluginStarted {
+		return res
+	}
+
+	app.cfgMu.RLock()
+	timeout := app.cfg.Health.Timeout
+	app.cfgMu.RUnlock()
+
+	t1 := time.Now()
+	err := app.callWithTimeout(timeout, checker.PluginHealth)
+	res.Elapsed = time.Since(t1)
+
+	if err != nil {
+		app.logPanic(err, "plugin", entry.name)
+		res.Healthy = false
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+// watchHealth periodically runs health checks until the app context is done,
+// logging plugins which change their health status.
+func (app *AppCtx[T, U]) watchHealth() {
+	interval := app.cfg.Health.Interval
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		prev := map[string]bool{}
+		for _, entry := range app.plugins {
+			prev[entry.name] = true
+		}
+
+		for {
+			select {
+			case <-app.Done():
+				return
+			case <-ticker.C:
+			}
+
+			report := app.Health()
+
+			app.health.mu.Lock()
+			app.health.background = &report
+			app.health.mu.Unlock()
+
+			for name, res := range report.Plugins {
+				if res.Healthy == prev[name] {
+					continue
+				}
+
+				prev[name] = res.Healthy
+				if res.Healthy {
+					app.Log().Str("name", name).Msg("plugin is healthy again")
+				} else {
+					app.Warn().Str("name", name).Str("state", res.State).Str("error", res.Error).Msg("plugin is unhealthy")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,79 @@
+package appctx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type healthTestPlugin[T any, U any] struct {
+	name string
+	err  error
+}
+
+func (pl *healthTestPlugin[T, U]) PluginName() string {
+	return pl.name
+}
+
+func (pl *healthTestPlugin[T, U]) PluginHealth(_ *AppCtx[T, U]) error {
+	return pl.err
+}
+
+func TestAppHealth(t *testing.T) {
+	resetCommandlineFlags()
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(&healthTestPlugin[struct{}, struct{}]{name: "good"})
+	app.RegisterPlugin(&healthTestPlugin[struct{}, struct{}]{name: "bad", err: assert.AnError})
+	app.DisableConfig()
+	assert.False(t, app.Ready())
+	app.Run(func(app *AppCtx[struct{}, struct{}]) error {
+		assert.True(t, app.Ready())
+		assert.False(t, app.ShuttingDown())
+
+		report := app.Health()
+
+		assert.False(t, report.Healthy)
+		assert.True(t, report.Plugins["good"].Healthy)
+		assert.False(t, report.Plugins["bad"].Healthy)
+		assert.Equal(t, assert.AnError.Error(), report.Plugins["bad"].Error)
+		assert.Equal(t, "started", report.Plugins["bad"].State)
+
+		last, ok := app.LastHealth()
+		assert.True(t, ok)
+		assert.Equal(t, report, last)
+
+		_, ok = app.BackgroundHealth()
+		assert.False(t, ok)
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+	assert.False(t, app.Ready())
+	assert.True(t, app.ShuttingDown())
+	assert.False(t, app.Health().Plugins["good"].Healthy)
+}
+
+func TestAppBackgroundHealth(t *testing.T) {
+	resetCommandlineFlags()
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(&healthTestPlugin[struct{}, struct{}]{name: "bad", err: assert.AnError})
+	app.SetHealthChecks(time.Second, time.Millisecond)
+	app.DisableConfig()
+	app.Run(func(app *AppCtx[struct{}, struct{}]) error {
+		assert.Eventually(t, func() bool {
+			_, ok := app.BackgroundHealth()
+			return ok
+		}, time.Second, time.Millisecond)
+
+		report, _ := app.BackgroundHealth()
+		assert.False(t, report.Healthy)
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
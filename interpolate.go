@@ -0,0 +1,181 @@
+package appctx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+)
+
+// interpolateYAML expands ${VAR} and ${VAR:-default} references in scalars and resolves
+// "!env NAME" and "!file PATH" tags. Unquoted scalars are parsed once again after expansion,
+// so that e.g. "port: ${PORT}" is decoded as a number; contents of files are always strings.
+func interpolateYAML(node ast.Node) (ast.Node, error) {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		for _, value := range n.Values {
+			_, err := interpolateYAML(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case *ast.MappingValueNode:
+		value, err := interpolateYAML(n.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		n.Value = value
+	case *ast.SequenceNode:
+		for i, value := range n.Values {
+			value, err := interpolateYAML(value)
+			if err != nil {
+				return nil, err
+			}
+
+			n.Values[i] = value
+		}
+	case *ast.AnchorNode:
+		value, err := interpolateYAML(n.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		n.Value = value
+	case *ast.TagNode:
+		return interpolateTag(n)
+	case *ast.LiteralNode:
+		value, err := expandEnv(n.Value.Value)
+		if err != nil {
+			return nil, yamlError(n, err)
+		}
+
+		n.Value.Value = value
+	case *ast.StringNode:
+		value, err := expandEnv(n.Value)
+		if err != nil {
+			return nil, yamlError(n, err)
+		}
+
+		if value == n.Value {
+			return n, nil
+		}
+
+		if n.Token.Type == token.StringType {
+			return yamlScalar(value, n), nil
+		}
+
+		n.Value = value
+	}
+
+	return node, nil
+}
+
+func interpolateTag(n *ast.TagNode) (ast.Node, error) {
+	switch n.Start.Value {
+	case "!env":
+		arg, ok := n.Value.(*ast.StringNode)
+		if !ok {
+			return nil, yamlError(n, errors.New("!env expects a variable name"))
+		}
+
+		value, ok := os.LookupEnv(arg.Value)
+		if !ok {
+			return nil, yamlError(n, fmt.Errorf("environment variable \"%v\" is not set", arg.Value))
+		}
+
+		return yamlScalar(value, arg), nil
+	case "!file":
+		arg, ok := n.Value.(*ast.StringNode)
+		if !ok {
+			return nil, yamlError(n, errors.New("!file expects a file path"))
+		}
+
+		data, err := os.ReadFile(arg.Value)
+		if err != nil {
+			return nil, yamlError(n, fmt.Errorf("reading file \"%v\": %w", arg.Value, err))
+		}
+
+		arg.Value = strings.TrimSpace(string(data))
+		return arg, nil
+	default:
+		value, err := interpolateYAML(n.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		n.Value = value
+		return n, nil
+	}
+}
+
+// yamlScalar parses value as an unquoted YAML scalar located at the position of orig.
+// Values which are not scalars are kept as strings.
+func yamlScalar(value string, orig *ast.StringNode) ast.Node {
+	file, err := parser.ParseBytes([]byte(value), 0)
+	if err == nil && len(file.Docs) == 1 {
+		if scalar, ok := file.Docs[0].Body.(ast.ScalarNode); ok {
+			scalar.GetToken().Position = orig.GetToken().Position
+			return scalar
+		}
+	}
+
+	orig.Value = value
+	return orig
+}
+
+// expandEnv replaces ${VAR} and ${VAR:-default} with values of environment variables.
+// Default value is used if the variable is unset or empty; "$${" produces literal "${".
+func expandEnv(s string) (string, error) {
+	var b strings.Builder
+
+	for {
+		idx := strings.Index(s, "${")
+		if idx == -1 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		if idx > 0 && s[idx-1] == '$' {
+			b.WriteString(s[:idx-1] + "${")
+			s = s[idx+2:]
+			continue
+		}
+
+		b.WriteString(s[:idx])
+
+		end := strings.Index(s[idx:], "}")
+		if end == -1 {
+			return "", fmt.Errorf("unterminated variable reference \"%v\"", s[idx:])
+		}
+
+		ref := s[idx+2 : idx+end]
+		s = s[idx+end+1:]
+
+		name, def, hasDefault := strings.Cut(ref, ":-")
+		if name == "" {
+			return "", errors.New("empty variable name in \"${" + ref + "}\"")
+		}
+
+		value, ok := os.LookupEnv(name)
+		switch {
+		case value != "":
+		case hasDefault:
+			value = def
+		case !ok:
+			return "", fmt.Errorf("environment variable \"%v\" is not set", name)
+		}
+
+		b.WriteString(value)
+	}
+}
+
+// yamlError adds position of the node to the error the same way YAML decoder does.
+func yamlError(node ast.Node, err error) error {
+	pos := node.GetToken().Position
+	return fmt.Errorf("[%d:%d] %w", pos.Line, pos.Column, err)
+}
@@ -0,0 +1,95 @@
+package appctx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppConfigInterpolation(t *testing.T) {
+	type appConfig struct {
+		URL      string   `yaml:"url"`
+		Port     uint16   `yaml:"port"`
+		Enabled  bool     `yaml:"enabled"`
+		Name     string   `yaml:"name"`
+		Code     string   `yaml:"code"`
+		Password string   `yaml:"password"`
+		Hosts    []string `yaml:"hosts"`
+		Literal  string   `yaml:"literal"`
+		Escaped  string   `yaml:"escaped"`
+	}
+
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"password": "  s3cret\n",
+		"config.yml": "url: postgres://${DB_HOST}:${DB_PORT:-5432}/app\n" +
+			"port: ${PORT}\n" +
+			"enabled: !env ENABLED\n" +
+			"name: \"${NAME:-default}\"\n" +
+			"code: \"${CODE}\"\n" +
+			"password: !file " + filepath.Join(dir, "password") + "\n" +
+			"hosts: [!env DB_HOST, other]\n" +
+			"literal: |\n  host=${DB_HOST}\n" +
+			"escaped: $${DB_HOST}\n",
+	})
+
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("PORT", "8080")
+	t.Setenv("ENABLED", "true")
+	t.Setenv("CODE", "0123")
+
+	os.Args = []string{os.Args[0], "-c", filepath.Join(dir, "config.yml")}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, struct{}]("Test App", "1.0.0")
+	app.Run(func(app *AppCtx[appConfig, struct{}]) error {
+		assert.Equal(t, appConfig{
+			URL:      "postgres://db:5432/app",
+			Port:     8080,
+			Enabled:  true,
+			Name:     "default",
+			Code:     "0123",
+			Password: "s3cret",
+			Hosts:    []string{"db", "other"},
+			Literal:  "host=db\n",
+			Escaped:  "${DB_HOST}",
+		}, *app.C())
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
+
+func TestAppConfigInterpolationErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config string
+		err    string
+	}{
+		"missing variable":  {config: "debug: false\ntitle: ${MISSING_VAR}\n", err: "[2:8] environment variable \"MISSING_VAR\" is not set"},
+		"missing env tag":   {config: "title: !env MISSING_VAR\n", err: "[1:8] environment variable \"MISSING_VAR\" is not set"},
+		"missing file":      {config: "\n\ntitle: !file /nonexistent/secret\n", err: "[3:8] reading file \"/nonexistent/secret\""},
+		"unterminated":      {config: "title: ${OOPS\n", err: "[1:8] unterminated variable reference"},
+		"empty variable":    {config: "title: ${:-x}\n", err: "empty variable name"},
+		"env tag non-value": {config: "title: !env [A]\n", err: "!env expects a variable name"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yml")
+			require.NoError(t, os.WriteFile(configFile, []byte(tc.config), 0o600))
+
+			os.Args = []string{os.Args[0], "-c", configFile}
+			defer resetCommandlineFlags()
+
+			app := NewApp[struct {
+				Title string `yaml:"title"`
+			}, struct{}]("Test App", "1.0.0")
+
+			err := app.RunE(nil)
+			require.ErrorContains(t, err, tc.err)
+			require.ErrorContains(t, err, configFile)
+		})
+	}
+}
@@ -2,50 +2,146 @@ package appctx
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
 )
 
+// Logger returns the app logger. The logger is replaced when Reload toggles debug mode,
+// so it should be requested again rather than kept for a long time.
 func (app *AppCtx[T, U]) Logger() *zerolog.Logger {
-	return &app.logger
+	return app.logger.Load()
 }
 
 func (app *AppCtx[T, U]) Log() *zerolog.Event {
-	return app.logger.Info() //nolint:zerologlint
+	return app.Logger().Info() //nolint:zerologlint
 }
 
 func (app *AppCtx[T, U]) Warn() *zerolog.Event {
-	return app.logger.Warn() //nolint:zerologlint
+	return app.Logger().Warn() //nolint:zerologlint
 }
 
 func (app *AppCtx[T, U]) Debug() *zerolog.Event {
-	return app.logger.Debug() //nolint:zerologlint
+	return app.Logger().Debug() //nolint:zerologlint
 }
 
 func (app *AppCtx[T, U]) Error(errs ...error) *zerolog.Event {
 	if len(errs) == 0 {
-		return app.logger.Error() //nolint:zerologlint
+		return app.Logger().Error() //nolint:zerologlint
 	}
 
-	return app.logger.Error().Err(errors.Join(errs...)) //nolint:zerologlint
+	return app.Logger().Error().Err(errors.Join(errs...)) //nolint:zerologlint
 }
 
-func (app *AppCtx[_, _]) makeLogger() {
-	zerolog.TimeFieldFormat = time.RFC3339Nano
+// appLogWriter switches between JSON output and human-friendly debug output,
+// so that debug mode can be toggled while the app is running. Log level is set on the logger itself.
+type appLogWriter struct {
+	debug   atomic.Bool
+	out     io.Writer
+	console zerolog.ConsoleWriter
+}
 
-	app.logger = zerolog.New(os.Stdout)
-	if app.cfg.Debug {
-		app.logger = app.logger.Level(zerolog.DebugLevel).Output(zerolog.ConsoleWriter{
-			Out:        os.Stdout,
+func newAppLogWriter(out io.Writer, debug bool) *appLogWriter {
+	w := &appLogWriter{
+		out: out,
+		console: zerolog.ConsoleWriter{
+			Out:        out,
 			TimeFormat: "02.01.2006 15:04:05.000000",
-		})
-	} else {
-		app.logger = app.logger.Level(zerolog.InfoLevel)
+		},
+	}
+
+	w.debug.Store(debug)
+	return w
+}
+
+func (w *appLogWriter) setDebug(debug bool) {
+	w.debug.Store(debug)
+}
+
+func (w *appLogWriter) Write(p []byte) (int, error) {
+	if w.debug.Load() {
+		return w.console.Write(p)
+	}
+
+	return w.out.Write(p)
+}
+
+// SetLogOutput sets writers which receive log output instead of stdout.
+// Writers implementing Flush() error or Sync() error are flushed by Exit.
+func (app *AppCtx[_, _]) SetLogOutput(outputs ...io.Writer) {
+	app.logOutputs = outputs
+}
+
+// setTimeFieldFormat sets the global zerolog option only once, since goroutines
+// of previously created apps (e.g. abandoned plugin hooks) may still be logging.
+var setTimeFieldFormat sync.Once
+
+func (app *AppCtx[_, _]) makeLogger() {
+	setTimeFieldFormat.Do(func() {
+		zerolog.TimeFieldFormat = time.RFC3339Nano
+	})
+
+	var out io.Writer = os.Stdout
+	switch len(app.logOutputs) {
+	case 0:
+	case 1:
+		out = app.logOutputs[0]
+	default:
+		out = io.MultiWriter(app.logOutputs...)
 	}
 
-	app.logger = app.logger.With().Timestamp().Logger()
-	app.logger.Debug().Msg("logger: initialized")
+	app.logWriter = newAppLogWriter(out, app.cfg.Debug)
+	logger := zerolog.New(app.logWriter).Level(logLevel(app.cfg.Debug)).With().Timestamp().Logger()
+	app.logger.Store(&logger)
+	app.Debug().Msg("logger: initialized")
 	app.hasLogger = true
 }
+
+// setDebug switches level and format of the logger. The logger is replaced rather than modified,
+// so that goroutines which are logging at the moment are not affected.
+func (app *AppCtx[_, _]) setDebug(debug bool) {
+	app.logWriter.setDebug(debug)
+
+	logger := app.Logger().Level(logLevel(debug))
+	app.logger.Store(&logger)
+}
+
+func logLevel(debug bool) zerolog.Level {
+	if debug {
+		return zerolog.DebugLevel
+	}
+
+	return zerolog.InfoLevel
+}
+
+// logError reports an error through the logger, or prints it to stdout
+// if the logger is not initialized yet.
+func (app *AppCtx[_, _]) logError(err error, msg string) {
+	if app.hasLogger {
+		app.Logger().Err(err).Msg(msg)
+	} else {
+		fmt.Println("ERROR: " + err.Error())
+	}
+}
+
+// flushLogs flushes log outputs which buffer their data.
+func (app *AppCtx[_, _]) flushLogs() {
+	outputs := app.logOutputs
+	if len(outputs) == 0 {
+		outputs = []io.Writer{os.Stdout}
+	}
+
+	for _, out := range outputs {
+		switch w := out.(type) {
+		case interface{ Flush() error }:
+			_ = w.Flush()
+		case interface{ Sync() error }:
+			_ = w.Sync()
+		}
+	}
+}
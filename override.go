@@ -0,0 +1,46 @@
+package appctx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// applyOverrides applies values given by repeatable --set key.path=value flags to the set.
+// Values are parsed as YAML into types of the fields.
+func (app *AppCtx[T, U]) applyOverrides(set *configSet[T, U], sources map[string]ConfigSource) error {
+	for _, override := range app.overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return fmt.Errorf("override \"%v\": expected key.path=value", override)
+		}
+
+		var target configField
+		found := false
+
+		_ = set.walk(func(f configField) error {
+			if f.key() == key {
+				target, found = f, true
+			}
+
+			return nil
+		})
+
+		if !found {
+			return fmt.Errorf("override \"%v\": unknown config path \"%v\"", override, key)
+		}
+
+		p := reflect.New(target.value.Type())
+		err := yaml.Unmarshal([]byte(value), p.Interface())
+		if err != nil {
+			return fmt.Errorf("override \"%v\": %w", override, err)
+		}
+
+		target.value.Set(p.Elem())
+		sources[key] = SourceFlag
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package appctx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppOverrides(t *testing.T) {
+	type appConfig struct {
+		Name string   `yaml:"name"`
+		Tags []string `yaml:"tags"`
+	}
+
+	type appPlugins struct {
+		Reload reloadTestPlugin[appConfig, appPlugins] `yaml:",inline"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("name: file\nlimit: 1\n"), 0o600))
+
+	t.Setenv("APP_NAME", "env")
+
+	os.Args = []string{
+		os.Args[0], "-c", configFile,
+		"--set", "name=123", "--set", "tags=[a, b]", "--set=limit=5", "--set", "timeouts.start=10s",
+	}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, appPlugins]("Test App", "1.0.0")
+	app.SetEnvPrefix("APP")
+	app.RegisterPlugin(&app.P().Reload)
+	app.Run(func(app *AppCtx[appConfig, appPlugins]) error {
+		assert.Equal(t, appConfig{Name: "123", Tags: []string{"a", "b"}}, *app.C())
+		assert.Equal(t, 5, app.P().Reload.Limit)
+		assert.Equal(t, 10*time.Second, app.cfg.Timeouts.Start)
+		assert.Equal(t, SourceFlag, app.ConfigSource("limit"))
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
+
+func TestAppOverrideErrors(t *testing.T) {
+	for name, override := range map[string]string{
+		"unknown path":  "timeouts.unknown=1s",
+		"not a value":   "timeouts=1s",
+		"invalid value": "timeouts.start=soon",
+		"missing value": "debug",
+	} {
+		t.Run(name, func(t *testing.T) {
+			resetCommandlineFlags()
+			os.Args = append(os.Args, "--set", override)
+
+			app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+			app.DisableConfig()
+
+			err := app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+				return nil
+			})
+			require.ErrorContains(t, err, override)
+			assert.Equal(t, ExitCodeConfig, ExitCodeOf(err))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package appctx
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned in place of a panic recovered from a plugin hook,
+// the Run callback, a service or a worker.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// callRecovering calls fn on the app, converting panics into PanicError.
+func (app *AppCtx[T, U]) callRecovering(fn func(ac *AppCtx[T, U]) error) (err error) {
+	defer func() {
+		e := recover()
+		if e != nil {
+			err = &PanicError{
+				Value: e,
+				Stack: debug.Stack(),
+			}
+		}
+	}()
+
+	return fn(app)
+}
+
+// logPanic logs the stack trace if the error was caused by a panic.
+// Key and name identify the component which has panicked, e.g. "plugin" and its name.
+// Panics which happen before the logger is initialized are printed to stdout.
+func (app *AppCtx[T, U]) logPanic(err error, key, name string) {
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		return
+	}
+
+	if !app.hasLogger {
+		fmt.Printf("ERROR: recovered from panic (%v %v): %v\n%s\n", key, name, panicErr.Value, panicErr.Stack)
+		return
+	}
+
+	app.Error(panicErr).Str(key, name).Str("stack", string(panicErr.Stack)).Msg("recovered from panic")
+}
@@ -0,0 +1,120 @@
+package appctx
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type panickingTestPlugin[T any, U any] struct {
+	orderTestPlugin[T, U]
+	onStart bool
+}
+
+func (pl *panickingTestPlugin[T, U]) PluginStart(_ *AppCtx[T, U]) error {
+	if pl.onStart {
+		panic("start failed")
+	}
+
+	*pl.log = append(*pl.log, "start "+pl.name)
+	return nil
+}
+
+func (pl *panickingTestPlugin[T, U]) PluginStop(_ *AppCtx[T, U]) {
+	panic("stop failed")
+}
+
+func TestPluginPanics(t *testing.T) {
+	resetCommandlineFlags()
+
+	log := []string{}
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "first", log: &log})
+	app.RegisterPlugin(&panickingTestPlugin[struct{}, struct{}]{orderTestPlugin: orderTestPlugin[struct{}, struct{}]{name: "second", log: &log}})
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "third", log: &log})
+	app.RegisterPlugin(&panickingTestPlugin[struct{}, struct{}]{orderTestPlugin: orderTestPlugin[struct{}, struct{}]{name: "fourth", log: &log}, onStart: true})
+	app.DisableConfig()
+	err := app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.Equal(t, ExitCodePluginStart, ExitCodeOf(err))
+	assert.Equal(t, []string{"start first", "start second", "start third", "stop third", "stop first"}, log)
+}
+
+func TestCallbackPanic(t *testing.T) {
+	resetCommandlineFlags()
+
+	log := []string{}
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "plugin", log: &log})
+	app.DisableConfig()
+	err := app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+		panic(assert.AnError)
+	})
+
+	require.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, ExitCodeError, ExitCodeOf(err))
+	assert.Equal(t, []string{"start plugin", "stop plugin"}, log)
+}
+
+type instantiatePanicTestPlugin[T any, U any] struct{}
+
+func (pl *instantiatePanicTestPlugin[T, U]) PluginName() string {
+	return "kaboom"
+}
+
+func (pl *instantiatePanicTestPlugin[T, U]) PluginInstantiate(_ *AppCtx[T, U]) error {
+	panic("kaboom")
+}
+
+// captureStdout returns everything written to stdout by fn.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	out := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- data
+	}()
+
+	fn()
+	require.NoError(t, w.Close())
+	return string(<-out)
+}
+
+func TestPanicBeforeLogger(t *testing.T) {
+	resetCommandlineFlags()
+
+	var err error
+	out := captureStdout(t, func() {
+		app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+		app.RegisterPlugin(&instantiatePanicTestPlugin[struct{}, struct{}]{})
+		app.DisableConfig()
+		err = app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+			return nil
+		})
+	})
+
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Contains(t, out, "recovered from panic (plugin kaboom): kaboom")
+	assert.Contains(t, out, "PluginInstantiate")
+}
@@ -1,21 +1,94 @@
 package appctx
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+type pluginState int32
+
+const (
+	pluginRegistered pluginState = iota
+	pluginInstantiated
+	pluginStarting
+	pluginStarted
+	pluginFailed
+	pluginStopping
+	pluginStopped
+	pluginDisabled
+)
+
+func (s pluginState) String() string {
+	switch s {
+	case pluginRegistered:
+		return "registered"
+	case pluginInstantiated:
+		return "instantiated"
+	case pluginStarting:
+		return "starting"
+	case pluginStarted:
+		return "started"
+	case pluginFailed:
+		return "failed"
+	case pluginStopping:
+		return "stopping"
+	case pluginStopped:
+		return "stopped"
+	case pluginDisabled:
+		return "disabled"
+	default:
+		return "unknown"
+	}
+}
+
+// appPluginEntry tracks lifecycle of a single registered plugin.
+type appPluginEntry[T any, U any] struct {
+	plugin  AppPlugin[T, U]
+	name    string
+	section string
+	deps    []string
+	level   int
+	state   atomic.Int32
+
+	// defaults of the plugin section, see WithSection
+	defaults reflect.Value
+}
+
+func (e *appPluginEntry[T, U]) getState() pluginState {
+	return pluginState(e.state.Load())
+}
+
+func (e *appPluginEntry[T, U]) setState(state pluginState) {
+	e.state.Store(int32(state))
+}
+
 type AppPlugin[T any, U any] interface {
 	PluginName() string
 }
 
-func (app *AppCtx[T, U]) RegisterPlugin(plugin AppPlugin[T, U]) {
-	app.registeredPlugins = append(app.registeredPlugins, plugin)
+type appPluginRegistration[T any, U any] struct {
+	plugin AppPlugin[T, U]
+	opts   pluginOptions
 }
 
-func (app *AppCtx[T, U]) WithPlugin(plugin AppPlugin[T, U]) *AppCtx[T, U] {
-	app.RegisterPlugin(plugin)
+func (app *AppCtx[T, U]) RegisterPlugin(plugin AppPlugin[T, U], opts ...PluginOption) {
+	reg := appPluginRegistration[T, U]{plugin: plugin}
+	for _, opt := range opts {
+		opt(&reg.opts)
+	}
+
+	app.registeredPlugins = append(app.registeredPlugins, reg)
+}
+
+func (app *AppCtx[T, U]) WithPlugin(plugin AppPlugin[T, U], opts ...PluginOption) *AppCtx[T, U] {
+	app.RegisterPlugin(plugin, opts...)
 	return app
 }
 
@@ -24,6 +97,10 @@ type appPluginInstantiator[T any, U any] interface {
 	PluginInstantiate(ac *AppCtx[T, U]) error
 }
 
+// appPluginStarter is implemented by plugins which acquire resources on start.
+// PluginStart should return promptly once the context of ac is done (on start timeout
+// or cancellation of the app). If it succeeds after it has been abandoned, the plugin
+// is stopped right away, but shutdown of the app does not wait for that.
 type appPluginStarter[T any, U any] interface {
 	AppPlugin[T, U]
 	PluginStart(ac *AppCtx[T, U]) error
@@ -34,60 +111,308 @@ type appPluginStopper[T any, U any] interface {
 	PluginStop(ac *AppCtx[T, U])
 }
 
-func (app *AppCtx[T, U]) startPlugins() error {
+// appPluginFallibleStopper is an alternative to appPluginStopper for plugins
+// which are able to report errors during shutdown.
+type appPluginFallibleStopper[T any, U any] interface {
+	AppPlugin[T, U]
+	PluginStop(ac *AppCtx[T, U]) error
+}
+
+// appPluginDependent is implemented by plugins which require other plugins
+// (referenced by their PluginName()) to be started before them.
+type appPluginDependent[T any, U any] interface {
+	AppPlugin[T, U]
+	PluginDependsOn() []string
+}
+
+// orderPlugins sorts registered plugins topologically by their dependencies.
+// Plugins without ordering constraints between them keep their registration order.
+func (app *AppCtx[T, U]) orderPlugins() error {
+	byName := map[string]*appPluginEntry[T, U]{}
+	entries := make([]*appPluginEntry[T, U], 0, len(app.registeredPlugins))
+
+	for _, reg := range app.registeredPlugins {
+		plugin := reg.plugin
+		entry := &appPluginEntry[T, U]{
+			plugin:  plugin,
+			name:    plugin.PluginName(),
+			section: reg.opts.section,
+		}
+
+		if entry.section != "" {
+			pv := reflect.ValueOf(plugin)
+			if pv.Kind() != reflect.Pointer || pv.IsNil() || pv.Elem().Kind() != reflect.Struct {
+				return fmt.Errorf("plugin \"%v\" with config section must be a pointer to struct", entry.section)
+			}
+
+			entry.name = entry.section
+		}
+
+		if _, ok := byName[entry.name]; ok {
+			return fmt.Errorf("plugin \"%v\" is registered more than once", entry.name)
+		}
+
+		if dependent, ok := plugin.(appPluginDependent[T, U]); ok {
+			entry.deps = dependent.PluginDependsOn()
+		}
+
+		byName[entry.name] = entry
+		entries = append(entries, entry)
+	}
+
+	for _, entry := range entries {
+		for _, dep := range entry.deps {
+			if _, ok := byName[dep]; !ok {
+				return fmt.Errorf("plugin \"%v\" depends on unknown plugin \"%v\"", entry.name, dep)
+			}
+		}
+	}
+
+	ordered := make([]*appPluginEntry[T, U], 0, len(entries))
+	placed := map[string]bool{}
+
+	for len(entries) > 0 {
+		idx := slices.IndexFunc(entries, func(entry *appPluginEntry[T, U]) bool {
+			for _, dep := range entry.deps {
+				if !placed[dep] {
+					return false
+				}
+			}
+
+			return true
+		})
+		if idx == -1 {
+			return fmt.Errorf("plugin dependency cycle: %v", findPluginCycle(entries[0], byName, placed))
+		}
+
+		entry := entries[idx]
+		for _, dep := range entry.deps {
+			entry.level = max(entry.level, byName[dep].level+1)
+		}
+
+		placed[entry.name] = true
+		ordered = append(ordered, entry)
+		entries = slices.Delete(entries, idx, idx+1)
+	}
+
+	app.plugins = ordered
+	return nil
+}
+
+// findPluginCycle follows unplaced dependencies starting from the given plugin
+// until some plugin is visited twice, and returns the resulting cycle.
+func findPluginCycle[T any, U any](entry *appPluginEntry[T, U], byName map[string]*appPluginEntry[T, U], placed map[string]bool) string {
+	path := []string{}
+	visited := map[string]int{}
+
+	for {
+		if idx, ok := visited[entry.name]; ok {
+			return strings.Join(append(path[idx:], entry.name), " -> ")
+		}
+
+		visited[entry.name] = len(path)
+		path = append(path, entry.name)
+
+		for _, dep := range entry.deps {
+			if !placed[dep] {
+				entry = byName[dep]
+				break
+			}
+		}
+	}
+}
+
+// EnableConcurrentStart makes plugins without ordering constraints between them
+// (i.e. plugins on the same dependency level) instantiate and start concurrently.
+func (app *AppCtx[T, U]) EnableConcurrentStart() {
+	app.concurrentStart = true
+}
+
+// pluginGroups splits ordered plugins into groups which can be processed concurrently.
+func (app *AppCtx[T, U]) pluginGroups() [][]*appPluginEntry[T, U] {
+	if !app.concurrentStart {
+		return [][]*appPluginEntry[T, U]{app.plugins}
+	}
+
+	groups := [][]*appPluginEntry[T, U]{}
+	for _, entry := range app.plugins {
+		for len(groups) <= entry.level {
+			groups = append(groups, nil)
+		}
+
+		groups[entry.level] = append(groups[entry.level], entry)
+	}
+
+	return groups
+}
+
+// forEachPlugin calls fn for each plugin group by group. If failFast is set, it stops
+// at the first failure, otherwise all plugins are processed and their errors are joined together.
+// Plugins within a group are processed concurrently if concurrent start is enabled,
+// in which case errors of the whole group are joined together.
+func (app *AppCtx[T, U]) forEachPlugin(fn func(entry *appPluginEntry[T, U]) error, failFast bool) error {
 	errs := []error{}
 
-	for _, rawPlugin := range app.registeredPlugins {
-		plugin, ok := rawPlugin.(appPluginStarter[T, U])
-		if !ok {
+	for _, group := range app.pluginGroups() {
+		if !app.concurrentStart {
+			for _, entry := range group {
+				err := fn(entry)
+				if err != nil && failFast {
+					return err
+				}
+
+				errs = append(errs, err)
+			}
+
 			continue
 		}
 
-		name := plugin.PluginName()
-		app.Debug().Str("name", name).Msg("starting plugin")
+		groupErrs := make([]error, len(group))
 
-		err := plugin.PluginStart(app)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("starting plugin \"%v\": %w", name, err))
+		var wg sync.WaitGroup
+		for i, entry := range group {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				groupErrs[i] = fn(entry)
+			}()
 		}
+		wg.Wait()
+
+		err := errors.Join(groupErrs...)
+		if err != nil && failFast {
+			return err
+		}
+
+		errs = append(errs, err)
 	}
 
 	return errors.Join(errs...)
 }
 
+// startPlugins starts plugins and aborts at the first failure.
+// Plugins which were started successfully are stopped later by stopPlugins.
+func (app *AppCtx[T, U]) startPlugins() error {
+	return app.forEachPlugin(app.startPlugin, true)
+}
+
+func (app *AppCtx[T, U]) startPlugin(entry *appPluginEntry[T, U]) error {
+	if entry.getState() == pluginDisabled {
+		return nil
+	}
+
+	plugin, ok := entry.plugin.(appPluginStarter[T, U])
+	if !ok {
+		entry.setState(pluginStarted)
+		return nil
+	}
+
+	app.Debug().Str("name", entry.name).Msg("starting plugin")
+	entry.setState(pluginStarting)
+	t1 := time.Now()
+
+	timeout, _ := app.pluginTimeouts(entry.name)
+	err := app.callWatchingTimeout(timeout, plugin.PluginStart, func(err error) {
+		app.stopAbandonedPlugin(entry, err)
+	})
+	if err != nil {
+		app.logPanic(err, "plugin", entry.name)
+		entry.setState(pluginFailed)
+		return &PluginError{Op: "starting", Name: entry.name, Err: err}
+	}
+
+	entry.setState(pluginStarted)
+	app.Debug().Str("name", entry.name).Dur("elapsed", time.Since(t1)).Msg("plugin started")
+	return nil
+}
+
+// stopAbandonedPlugin stops the plugin whose start hook has returned successfully after
+// the start was abandoned due to a timeout or cancellation, so that resources it has acquired are released.
+// The plugin stays failed, so that stopPlugins does not stop it once again.
+func (app *AppCtx[T, U]) stopAbandonedPlugin(entry *appPluginEntry[T, U], err error) {
+	if err != nil {
+		app.logPanic(err, "plugin", entry.name)
+		return
+	}
+
+	app.Warn().Str("name", entry.name).Msg("plugin started after its start was abandoned, stopping it")
+
+	err = app.stopPlugin(entry)
+	if err != nil {
+		app.Error(err).Str("name", entry.name).Msg("failed to stop plugin")
+	}
+
+	entry.setState(pluginFailed)
+}
+
+// instantiatePlugins instantiates all plugins, reporting errors of all of them at once.
 func (app *AppCtx[T, U]) instantiatePlugins() error {
+	return app.forEachPlugin(app.instantiatePlugin, false)
+}
+
+func (app *AppCtx[T, U]) instantiatePlugin(entry *appPluginEntry[T, U]) error {
+	plugin, ok := entry.plugin.(appPluginInstantiator[T, U])
+	if !ok {
+		entry.setState(pluginInstantiated)
+		return nil
+	}
+
+	app.Debug().Str("name", entry.name).Msg("instantiating plugin")
+
+	err := app.callRecovering(plugin.PluginInstantiate)
+	if err != nil {
+		app.logPanic(err, "plugin", entry.name)
+		entry.setState(pluginFailed)
+		return &PluginError{Op: "instantiating", Name: entry.name, Err: err}
+	}
+
+	entry.setState(pluginInstantiated)
+	return nil
+}
+
+// stopPlugins stops plugins which were started successfully, in reverse order.
+func (app *AppCtx[T, U]) stopPlugins() error {
 	errs := []error{}
 
-	for _, rawPlugin := range app.registeredPlugins {
-		plugin, ok := rawPlugin.(appPluginInstantiator[T, U])
-		if !ok {
+	for i := len(app.plugins) - 1; i >= 0; i-- {
+		entry := app.plugins[i]
+		if entry.getState() != pluginStarted {
 			continue
 		}
 
-		name := plugin.PluginName()
-		app.Debug().Str("name", name).Msg("instantiating plugin")
-
-		err := plugin.PluginInstantiate(app)
+		err := app.stopPlugin(entry)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("instantiating plugin \"%v\": %w", name, err))
+			errs = append(errs, err)
 		}
 	}
 
 	return errors.Join(errs...)
 }
 
-func (app *AppCtx[T, U]) stopPlugins() {
-	plugins := app.registeredPlugins
-	slices.Reverse(plugins)
+func (app *AppCtx[T, U]) stopPlugin(entry *appPluginEntry[T, U]) error {
+	app.Debug().Str("name", entry.name).Msg("stopping plugin")
+	entry.setState(pluginStopping)
+	defer entry.setState(pluginStopped)
 
-	for _, rawPlugin := range plugins {
-		plugin, ok := rawPlugin.(appPluginStopper[T, U])
-		if !ok {
-			continue
-		}
+	// stop hooks should not observe cancellation of the app context, only their own timeout
+	stopApp := app.WithContext(context.WithoutCancel(app))
+	_, timeout := app.pluginTimeouts(entry.name)
 
-		app.Debug().Str("name", plugin.PluginName()).Msg("stopping plugin")
+	var err error
+	switch plugin := entry.plugin.(type) {
+	case appPluginStopper[T, U]:
+		err = stopApp.callWithTimeout(timeout, func(ac *AppCtx[T, U]) error {
+			plugin.PluginStop(ac)
+			return nil
+		})
+	case appPluginFallibleStopper[T, U]:
+		err = stopApp.callWithTimeout(timeout, plugin.PluginStop)
+	}
 
-		plugin.PluginStop(app)
+	if err != nil {
+		app.logPanic(err, "plugin", entry.name)
+		return &PluginError{Op: "stopping", Name: entry.name, Err: err}
 	}
+
+	return nil
 }
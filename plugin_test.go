@@ -0,0 +1,193 @@
+package appctx
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderTestPlugin[T any, U any] struct {
+	name string
+	deps []string
+	log  *[]string
+}
+
+func (pl *orderTestPlugin[T, U]) PluginName() string {
+	return pl.name
+}
+
+func (pl *orderTestPlugin[T, U]) PluginDependsOn() []string {
+	return pl.deps
+}
+
+func (pl *orderTestPlugin[T, U]) PluginStart(_ *AppCtx[T, U]) error {
+	*pl.log = append(*pl.log, "start "+pl.name)
+	return nil
+}
+
+func (pl *orderTestPlugin[T, U]) PluginStop(_ *AppCtx[T, U]) {
+	*pl.log = append(*pl.log, "stop "+pl.name)
+}
+
+func TestPluginDependencyOrder(t *testing.T) {
+	resetCommandlineFlags()
+
+	log := []string{}
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "handlers", deps: []string{"db", "cache"}, log: &log})
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "cache", log: &log})
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "db", deps: []string{"cache"}, log: &log})
+	app.DisableConfig()
+	app.Run(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+
+	require.False(t, app.hasError)
+	assert.Equal(t, []string{
+		"start cache", "start db", "start handlers",
+		"stop handlers", "stop db", "stop cache",
+	}, log)
+}
+
+func TestPluginDependencyErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		plugins map[string][]string
+	}{
+		{"cycle", map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}}},
+		{"missing", map[string][]string{"a": {"unknown"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resetCommandlineFlags()
+
+			log := []string{}
+			called := false
+
+			app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+			for name, deps := range tc.plugins {
+				app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: name, deps: deps, log: &log})
+			}
+			app.DisableConfig()
+			app.Run(func(_ *AppCtx[struct{}, struct{}]) error {
+				called = true
+				return nil
+			})
+
+			require.True(t, app.hasError)
+			assert.False(t, called)
+			assert.Empty(t, log)
+		})
+	}
+}
+
+type failingTestPlugin[T any, U any] struct {
+	orderTestPlugin[T, U]
+}
+
+func (pl *failingTestPlugin[T, U]) PluginStart(_ *AppCtx[T, U]) error {
+	*pl.log = append(*pl.log, "fail "+pl.name)
+	return assert.AnError
+}
+
+func TestPluginStartRollback(t *testing.T) {
+	resetCommandlineFlags()
+
+	log := []string{}
+	called := false
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "first", log: &log})
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "second", log: &log})
+	app.RegisterPlugin(&failingTestPlugin[struct{}, struct{}]{orderTestPlugin[struct{}, struct{}]{name: "third", log: &log}})
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "fourth", log: &log})
+	app.DisableConfig()
+	app.Run(func(_ *AppCtx[struct{}, struct{}]) error {
+		called = true
+		return nil
+	})
+
+	require.True(t, app.hasError)
+	assert.False(t, called)
+	assert.Equal(t, []string{"start first", "start second", "fail third", "stop second", "stop first"}, log)
+}
+
+type instantiateTestPlugin[T any, U any] struct {
+	orderTestPlugin[T, U]
+	err error
+}
+
+func (pl *instantiateTestPlugin[T, U]) PluginInstantiate(_ *AppCtx[T, U]) error {
+	*pl.log = append(*pl.log, "instantiate "+pl.name)
+	return pl.err
+}
+
+func TestPluginInstantiateErrors(t *testing.T) {
+	resetCommandlineFlags()
+
+	log := []string{}
+	errFirst, errThird := errors.New("first failed"), errors.New("third failed")
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(&instantiateTestPlugin[struct{}, struct{}]{orderTestPlugin[struct{}, struct{}]{name: "first", log: &log}, errFirst})
+	app.RegisterPlugin(&instantiateTestPlugin[struct{}, struct{}]{orderTestPlugin[struct{}, struct{}]{name: "second", log: &log}, nil})
+	app.RegisterPlugin(&instantiateTestPlugin[struct{}, struct{}]{orderTestPlugin[struct{}, struct{}]{name: "third", log: &log}, errThird})
+	app.DisableConfig()
+	err := app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+
+	require.ErrorIs(t, err, errFirst)
+	require.ErrorIs(t, err, errThird)
+	assert.Equal(t, ExitCodePluginStart, ExitCodeOf(err))
+	assert.Equal(t, []string{"instantiate first", "instantiate second", "instantiate third"}, log)
+}
+
+type barrierTestPlugin[T any, U any] struct {
+	name  string
+	deps  []string
+	ready chan struct{}
+	other chan struct{}
+}
+
+func (pl *barrierTestPlugin[T, U]) PluginName() string {
+	return pl.name
+}
+
+func (pl *barrierTestPlugin[T, U]) PluginDependsOn() []string {
+	return pl.deps
+}
+
+func (pl *barrierTestPlugin[T, U]) PluginStart(_ *AppCtx[T, U]) error {
+	close(pl.ready)
+
+	select {
+	case <-pl.other:
+		return nil
+	case <-time.After(time.Second):
+		return errors.New("plugins were not started concurrently")
+	}
+}
+
+func TestPluginConcurrentStart(t *testing.T) {
+	resetCommandlineFlags()
+
+	a, b := make(chan struct{}), make(chan struct{})
+	log := []string{}
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(&orderTestPlugin[struct{}, struct{}]{name: "base", log: &log})
+	app.RegisterPlugin(&barrierTestPlugin[struct{}, struct{}]{name: "a", deps: []string{"base"}, ready: a, other: b})
+	app.RegisterPlugin(&barrierTestPlugin[struct{}, struct{}]{name: "b", deps: []string{"base"}, ready: b, other: a})
+	app.EnableConcurrentStart()
+	app.DisableConfig()
+	app.Run(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+
+	require.False(t, app.hasError)
+	assert.Equal(t, []string{"start base", "stop base"}, log)
+}
@@ -9,7 +9,7 @@ import (
 )
 
 func (pl *PluginGORM[T, U]) testDBFeatures(app *appctx.AppCtx[T, U]) error {
-	err := pl.db.Transaction(func(tx *gorm.DB) error {
+	err := pl.db.WithContext(app).Transaction(func(tx *gorm.DB) error {
 		var res int
 		err := tx.Raw("SELECT (1 + 1);").Scan(&res).Error
 		if err != nil || res != 2 {
@@ -13,37 +13,48 @@ import (
 )
 
 type PluginGORM[T any, U any] struct {
-	DatabaseURL           string        `yaml:"database_url"`
-	TraceSQL              bool          `yaml:"trace_sql"`
-	MaxConnectionLifetime time.Duration `yaml:"max_connection_lifetime"`
-	MaxOpenConnections    int           `yaml:"max_open_connections"`
+	DatabaseURL           appctx.Secret[string] `yaml:"database_url" validate:"required"`
+	TraceSQL              bool                  `yaml:"trace_sql"`
+	MaxConnectionLifetime time.Duration         `yaml:"max_connection_lifetime"`
+	MaxOpenConnections    int                   `yaml:"max_open_connections" validate:"min=1"`
 
-	db *gorm.DB
+	db  *gorm.DB
+	app *appctx.AppCtx[T, U]
 }
 
 func (pl *PluginGORM[T, U]) PluginName() string {
 	return "gorm"
 }
 
-func (pl *PluginGORM[T, U]) PluginInstantiate(_ *appctx.AppCtx[T, U]) error {
+func (pl *PluginGORM[T, U]) PluginInstantiate(app *appctx.AppCtx[T, U]) error {
+	pl.app = app
+
 	pl.MaxConnectionLifetime = 5 * time.Minute
 	pl.MaxOpenConnections = 10
 	return nil
 }
 
+func (pl *PluginGORM[T, U]) PluginFlags(app *appctx.AppCtx[T, U]) {
+	app.Flag("database-url", &pl.DatabaseURL, pl.DatabaseURL, "database connection URL")
+	app.Flag("trace-sql", &pl.TraceSQL, pl.TraceSQL, "log SQL queries")
+	app.Flag("max-connection-lifetime", &pl.MaxConnectionLifetime, pl.MaxConnectionLifetime, "maximum lifetime of connections")
+	app.Flag("max-open-connections", &pl.MaxOpenConnections, pl.MaxOpenConnections, "maximum number of open connections")
+}
+
 func (pl *PluginGORM[T, U]) PluginStart(app *appctx.AppCtx[T, U]) error {
-	if pl.DatabaseURL == "" {
+	if pl.DatabaseURL.Reveal() == "" {
 		return errors.New("empty database URL")
 	}
 
-	db, err := gorm.Open(postgres.Open(pl.DatabaseURL), &gorm.Config{
+	db, err := gorm.Open(postgres.Open(pl.DatabaseURL.Reveal()), &gorm.Config{
 		Logger: NewLogger(app.Logger(), pl.TraceSQL),
 	})
 	if err != nil {
 		return fmt.Errorf("initializing db: %w", err)
 	}
 
-	pl.db = db.WithContext(app)
+	// app passed to PluginStart may be bound to the start timeout
+	pl.db = db.WithContext(pl.app)
 
 	sqlDB, err := pl.sqlDB()
 	if err != nil {
@@ -55,6 +66,10 @@ func (pl *PluginGORM[T, U]) PluginStart(app *appctx.AppCtx[T, U]) error {
 
 	err = pl.testDBFeatures(app)
 	if err != nil {
+		// plugins which failed to start are not stopped, so close the pool here
+		_ = sqlDB.Close()
+		pl.db = nil
+
 		return fmt.Errorf("testing db features: %w", err)
 	}
 
@@ -76,6 +91,41 @@ func (pl *PluginGORM[T, U]) PluginStop(app *appctx.AppCtx[T, U]) {
 	pl.db = nil
 }
 
+// PluginReconfigure applies connection pool limits live. Database URL can't be changed without restart.
+func (pl *PluginGORM[T, U]) PluginReconfigure(_ *appctx.AppCtx[T, U], _, next appctx.AppPlugin[T, U]) error {
+	nextPl, ok := next.(*PluginGORM[T, U])
+	if !ok {
+		return fmt.Errorf("unexpected plugin type %T", next)
+	}
+
+	if nextPl.DatabaseURL != pl.DatabaseURL {
+		return errors.New("database URL can't be changed without restart")
+	}
+
+	sqlDB, err := pl.sqlDB()
+	if err != nil {
+		return fmt.Errorf("getting SQL DB: %w", err)
+	}
+
+	sqlDB.SetConnMaxLifetime(nextPl.MaxConnectionLifetime)
+	sqlDB.SetMaxOpenConns(nextPl.MaxOpenConnections)
+	return nil
+}
+
+func (pl *PluginGORM[T, U]) PluginHealth(app *appctx.AppCtx[T, U]) error {
+	sqlDB, err := pl.sqlDB()
+	if err != nil {
+		return fmt.Errorf("getting SQL DB: %w", err)
+	}
+
+	err = sqlDB.PingContext(app)
+	if err != nil {
+		return fmt.Errorf("pinging db: %w", err)
+	}
+
+	return nil
+}
+
 func (pl *PluginGORM[T, U]) DB() *gorm.DB {
 	return pl.db
 }
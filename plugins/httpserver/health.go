@@ -0,0 +1,86 @@
+package httpserver
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// withHealthRoutes serves liveness and readiness probes before the request reaches the router,
+// so that they are not affected by user middlewares.
+func (pl *PluginHTTPServer[T, U]) withHealthRoutes(next http.Handler) http.Handler {
+	if !pl.HealthChecks {
+		return next
+	}
+
+	// handlers use copies of the settings, since the config may be replaced by a reload
+	livenessPath, readinessPath, token := pl.LivenessPath, pl.ReadinessPath, pl.HealthToken.Reveal()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch r.URL.Path {
+		case livenessPath:
+			pl.sendProbe(w, r, true, nil)
+		case readinessPath:
+			pl.serveReadiness(w, r, token)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func (pl *PluginHTTPServer[T, U]) serveReadiness(w http.ResponseWriter, r *http.Request, token string) {
+	ready := pl.app.Ready()
+
+	// use results of background health checks if they are enabled, on-demand checks made
+	// for detailed responses must not affect plain probes
+	report, ok := pl.app.BackgroundHealth()
+	if ok && !report.Healthy {
+		ready = false
+	}
+
+	if !healthDetailsAllowed(r, token) {
+		pl.sendProbe(w, r, ready, nil)
+		return
+	}
+
+	report = pl.app.Health()
+	pl.sendProbe(w, r, ready && report.Healthy, render.M{
+		"plugins": report.Plugins,
+	})
+}
+
+// healthDetailsAllowed checks whether per-plugin health details may be included in response.
+// If health token is configured, it must be passed as a bearer token,
+// otherwise it is enough to pass "debug" query parameter.
+func healthDetailsAllowed(r *http.Request, token string) bool {
+	if token != "" {
+		return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) == 1
+	}
+
+	return r.URL.Query().Has("debug")
+}
+
+func (pl *PluginHTTPServer[T, U]) sendProbe(w http.ResponseWriter, r *http.Request, ok bool, details render.M) {
+	res := render.M{
+		"ok": ok,
+	}
+
+	for k, v := range details {
+		res[k] = v
+	}
+
+	code := http.StatusOK
+	if !ok {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	render.DefaultResponder(w, r, res)
+}
@@ -0,0 +1,218 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Family-Team-2/appctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPlugins struct {
+	HTTP PluginHTTPServer[struct{}, testPlugins] `yaml:",inline"`
+}
+
+type testApp = appctx.AppCtx[struct{}, testPlugins]
+
+// probeTestPlugin queries the readiness probe while plugins are being started.
+type probeTestPlugin struct {
+	code int
+}
+
+func (pl *probeTestPlugin) PluginName() string {
+	return "probe"
+}
+
+func (pl *probeTestPlugin) PluginDependsOn() []string {
+	return []string{"httpserver"}
+}
+
+func (pl *probeTestPlugin) PluginStart(app *testApp) error {
+	pl.code, _ = probe(app.P().HTTP.srv.Handler, "/readyz", "")
+	return nil
+}
+
+// flakyTestPlugin reports health depending on its flag.
+type flakyTestPlugin struct {
+	failing atomic.Bool
+}
+
+func (pl *flakyTestPlugin) PluginName() string {
+	return "flaky"
+}
+
+func (pl *flakyTestPlugin) PluginHealth(_ *testApp) error {
+	if pl.failing.Load() {
+		return errors.New("dependency is down")
+	}
+
+	return nil
+}
+
+// runTestApp runs the app with the given config and calls fn while it is running.
+// The config file may be rewritten by fn to test reloads.
+func runTestApp(t *testing.T, config string, fn func(app *testApp, configFile string), plugins ...appctx.AppPlugin[struct{}, testPlugins]) {
+	t.Helper()
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte(config), 0o600))
+
+	args := os.Args
+	os.Args = []string{args[0], "-c", configFile}
+	defer func() {
+		os.Args = args
+	}()
+
+	app := appctx.NewApp[struct{}, testPlugins]("Test App", "1.0.0")
+	app.RegisterPlugin(&app.P().HTTP)
+	for _, pl := range plugins {
+		app.RegisterPlugin(pl)
+	}
+
+	err := app.RunE(func(app *testApp) error {
+		fn(app, configFile)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// probe requests the path and returns response code and decoded body.
+func probe(h http.Handler, path, token string) (int, map[string]any) {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	body := map[string]any{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body
+}
+
+func TestHealthReadiness(t *testing.T) {
+	pl := &probeTestPlugin{}
+
+	runTestApp(t, "", func(app *testApp, _ string) {
+		h := app.P().HTTP.srv.Handler
+
+		code, body := probe(h, "/readyz", "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]any{"ok": true}, body)
+
+		app.Stop()
+		assert.Eventually(t, func() bool {
+			code, _ := probe(h, "/readyz", "")
+			return code == http.StatusServiceUnavailable
+		}, time.Second, time.Millisecond)
+
+		code, _ = probe(h, "/healthz", "")
+		assert.Equal(t, http.StatusOK, code)
+	}, pl)
+
+	assert.Equal(t, http.StatusServiceUnavailable, pl.code)
+}
+
+func TestHealthDetails(t *testing.T) {
+	runTestApp(t, "", func(app *testApp, _ string) {
+		h := app.P().HTTP.srv.Handler
+
+		_, body := probe(h, "/readyz", "")
+		assert.NotContains(t, body, "plugins")
+
+		code, body := probe(h, "/readyz?debug", "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "plugins")
+	})
+
+	runTestApp(t, "health_token: secret\n", func(app *testApp, _ string) {
+		h := app.P().HTTP.srv.Handler
+
+		_, body := probe(h, "/readyz?debug", "")
+		assert.NotContains(t, body, "plugins")
+
+		_, body = probe(h, "/readyz", "wrong")
+		assert.NotContains(t, body, "plugins")
+
+		code, body := probe(h, "/readyz", "secret")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "plugins")
+	})
+}
+
+func TestHealthDetailsDoNotAffectProbes(t *testing.T) {
+	pl := &flakyTestPlugin{}
+
+	runTestApp(t, "", func(app *testApp, _ string) {
+		h := app.P().HTTP.srv.Handler
+
+		pl.failing.Store(true)
+		code, _ := probe(h, "/readyz?debug", "")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+
+		pl.failing.Store(false)
+		code, _ = probe(h, "/readyz", "")
+		assert.Equal(t, http.StatusOK, code)
+	}, pl)
+}
+
+func TestHealthConfig(t *testing.T) {
+	runTestApp(t, "liveness_path: /live\nreadiness_path: /ready\n", func(app *testApp, _ string) {
+		h := app.P().HTTP.srv.Handler
+
+		code, _ := probe(h, "/live", "")
+		assert.Equal(t, http.StatusOK, code)
+
+		code, _ = probe(h, "/ready", "")
+		assert.Equal(t, http.StatusOK, code)
+
+		code, _ = probe(h, "/readyz", "")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	runTestApp(t, "health_checks: false\n", func(app *testApp, _ string) {
+		code, _ := probe(app.P().HTTP.srv.Handler, "/healthz", "")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
+
+func TestHealthReload(t *testing.T) {
+	runTestApp(t, "health_token: one\n", func(app *testApp, configFile string) {
+		h := app.P().HTTP.srv.Handler
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+
+			for app.Ready() {
+				probe(h, "/healthz", "")
+				probe(h, "/readyz", "one")
+			}
+		}()
+
+		for i := range 10 {
+			config := fmt.Sprintf("health_token: token%d\nliveness_path: /live%d\nreadiness_path: /ready%d\nport: %d\n", i, i, i, 8000+i)
+			require.NoError(t, os.WriteFile(configFile, []byte(config), 0o600))
+			require.NoError(t, app.Reload())
+		}
+
+		// httpserver can't be reconfigured, new values take effect after restart
+		code, body := probe(h, "/readyz", "one")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "plugins")
+		assert.Equal(t, uint16(80), app.P().HTTP.Port)
+
+		app.Stop()
+		<-done
+	})
+}
@@ -3,6 +3,7 @@ package httpserver
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"strconv"
@@ -14,16 +15,22 @@ import (
 )
 
 type PluginHTTPServer[T any, U any] struct {
-	Host              string        `yaml:"host"`
-	Port              uint16        `yaml:"port"`
-	LogRequests       bool          `yaml:"log_requests"`
-	ReadTimeout       time.Duration `yaml:"read_timeout"`
-	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
-	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
-
-	srv *http.Server
-	rt  *chi.Mux
-	app *appctx.AppCtx[T, U]
+	Host              string                `yaml:"host"`
+	Port              uint16                `yaml:"port" validate:"min=1"`
+	LogRequests       bool                  `yaml:"log_requests"`
+	ReadTimeout       time.Duration         `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration         `yaml:"read_header_timeout"`
+	ShutdownTimeout   time.Duration         `yaml:"shutdown_timeout"`
+	HealthChecks      bool                  `yaml:"health_checks"`
+	LivenessPath      string                `yaml:"liveness_path"`
+	ReadinessPath     string                `yaml:"readiness_path"`
+	HealthToken       appctx.Secret[string] `yaml:"health_token"`
+	AutoServe         bool                  `yaml:"auto_serve"`
+
+	srv    *http.Server
+	rt     *chi.Mux
+	app    *appctx.AppCtx[T, U]
+	routes []func(r HTTPRouter[T, U])
 }
 
 func (pl *PluginHTTPServer[T, U]) MarshalZerologObject(e *zerolog.Event) {
@@ -41,25 +48,59 @@ func (pl *PluginHTTPServer[T, U]) PluginInstantiate(app *appctx.AppCtx[T, U]) er
 	pl.Port = 80
 	pl.ReadHeaderTimeout = 1 * time.Minute
 	pl.ShutdownTimeout = 5 * time.Second
+	pl.HealthChecks = true
+	pl.LivenessPath = "/healthz"
+	pl.ReadinessPath = "/readyz"
 	return nil
 }
 
+func (pl *PluginHTTPServer[T, U]) PluginFlags(app *appctx.AppCtx[T, U]) {
+	app.Flag("host", &pl.Host, pl.Host, "host to listen on")
+	app.Flag("port", &pl.Port, pl.Port, "port to listen on")
+	app.Flag("read-timeout", &pl.ReadTimeout, pl.ReadTimeout, "maximum duration for reading requests")
+	app.Flag("log-requests", &pl.LogRequests, pl.LogRequests, "log HTTP requests")
+}
+
 func (pl *PluginHTTPServer[T, U]) PluginStart(app *appctx.AppCtx[T, U]) error {
 	pl.buildRouter()
 
 	pl.srv = &http.Server{
 		Addr:    pl.Host + ":" + strconv.FormatUint(uint64(pl.Port), 10),
-		Handler: pl.rt,
+		Handler: pl.withHealthRoutes(pl.rt),
 		BaseContext: func(_ net.Listener) context.Context {
-			return app
+			// app passed to PluginStart may be bound to the start timeout
+			return pl.app
 		},
 		ReadTimeout:       pl.ReadTimeout,
 		ReadHeaderTimeout: pl.ReadHeaderTimeout,
 	}
 
+	if pl.AutoServe {
+		app.AddService(pl.PluginName(), pl.serve)
+	}
+
 	return nil
 }
 
+// serve runs http server until the app context is done.
+func (pl *PluginHTTPServer[T, U]) serve(app *appctx.AppCtx[T, U]) error {
+	pl.app.Debug().EmbedObject(pl).Msg("starting http server")
+
+	res := make(chan error, 1)
+	go func() {
+		res <- pl.srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-res:
+		return fmt.Errorf("serving http: %w", err)
+	case <-app.Done():
+		pl.StopServer()
+		<-res
+		return nil
+	}
+}
+
 func (pl *PluginHTTPServer[T, U]) StartServer() {
 	pl.app.Debug().EmbedObject(pl).Msg("starting http server")
 
@@ -67,7 +108,7 @@ func (pl *PluginHTTPServer[T, U]) StartServer() {
 		err := pl.srv.ListenAndServe()
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			pl.app.Error(err).Msg("failed to start http server")
-			pl.app.Stop()
+			pl.app.StopWithCause(fmt.Errorf("serving http: %w", err))
 		}
 	}()
 }
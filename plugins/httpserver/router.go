@@ -29,6 +29,11 @@ func (pl *PluginHTTPServer[T, U]) buildRouter() {
 	pl.rt.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
 		pl.SendHTTPError(w, r, &httpErrorMethodNotAllowed)
 	})
+
+	for _, callback := range pl.routes {
+		pl.DefineHTTPRoutes(callback)
+	}
+	pl.routes = nil
 }
 
 type HTTPRouter[T any, U any] interface {
@@ -113,7 +118,14 @@ func (rt *httpRouter[T, U]) Subroute(pattern string, callback func(sr HTTPRouter
 	})
 }
 
+// DefineHTTPRoutes adds routes to the router. If called before the plugin has started
+// (e.g. when the server is started automatically as a service), routes are added during PluginStart.
 func (pl *PluginHTTPServer[T, U]) DefineHTTPRoutes(callback func(r HTTPRouter[T, U])) {
+	if pl.rt == nil {
+		pl.routes = append(pl.routes, callback)
+		return
+	}
+
 	callback(&httpRouter[T, U]{
 		r:  pl.rt,
 		pl: pl,
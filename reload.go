@@ -0,0 +1,190 @@
+package appctx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// appPluginReconfigurer is implemented by plugins which can apply configuration changes
+// without restarting. The hook receives the plugin itself and its copy decoded from
+// the new configuration; returning an error rejects the whole new configuration.
+// Once all hooks accept the changes, fields of the plugin are replaced while holding
+// the config lock, so goroutines which read them concurrently (e.g. request handlers)
+// should either hold RLockConfig or use copies of the values taken by the plugin itself.
+type appPluginReconfigurer[T any, U any] interface {
+	AppPlugin[T, U]
+	PluginReconfigure(ac *AppCtx[T, U], prev, next AppPlugin[T, U]) error
+}
+
+// Reload re-reads the config file and applies it if all plugins accept the changes.
+// The custom config is replaced as a whole (see Config), while fields of plugins are updated
+// only if they implement PluginReconfigure; changes of other plugins take effect after restart.
+// Internal state of plugins is kept intact.
+// Reload is also triggered by SIGHUP if the app handles OS signals itself
+// (i.e. it was not created by NewAppWithContext).
+func (app *AppCtx[T, U]) Reload() error {
+	app = app.root
+
+	if app.noConfig {
+		return errors.New("config is disabled")
+	}
+
+	if !app.Ready() {
+		return errors.New("app is not running")
+	}
+
+	app.reloadMu.Lock()
+	defer app.reloadMu.Unlock()
+
+	cur, next := app.liveConfig(), app.defaultConfig()
+	sources, err := app.loadLayers(next)
+	if err != nil {
+		return err
+	}
+
+	err = app.validateConfig(next)
+	if err != nil {
+		return fmt.Errorf("validating config: %w", err)
+	}
+
+	errs := []error{}
+	accepted := map[*appPluginEntry[T, U]]bool{}
+	for _, entry := range app.plugins {
+		plugin, ok := entry.plugin.(appPluginReconfigurer[T, U])
+		if !ok {
+			continue
+		}
+
+		nextPlugin, ok := app.pluginConfig(cur, next, entry)
+		if !ok {
+			continue
+		}
+
+		err = app.callRecovering(func(ac *AppCtx[T, U]) error {
+			return plugin.PluginReconfigure(ac, entry.plugin, nextPlugin)
+		})
+		if err != nil {
+			app.logPanic(err, "plugin", entry.name)
+			errs = append(errs, &PluginError{Op: "reconfiguring", Name: entry.name, Err: err})
+			continue
+		}
+
+		accepted[entry] = true
+	}
+
+	err = errors.Join(errs...)
+	if err != nil {
+		return err
+	}
+
+	err = app.applyConfig(cur, next, accepted)
+	if err != nil {
+		return err
+	}
+
+	app.sources.Store(&sources)
+	app.Log().Msg("config: reloaded")
+	return nil
+}
+
+// applyConfig publishes the next custom config, and copies the rest of the next config
+// into the live one, skipping plugins which have not accepted the changes.
+func (app *AppCtx[T, U]) applyConfig(cur, next *configSet[T, U], accepted map[*appPluginEntry[T, U]]bool) error {
+	cfgType := reflect.TypeFor[appCfg[T, U]]()
+	customField, _ := cfgType.FieldByName("Custom")
+	pluginsField, _ := cfgType.FieldByName("Plugins")
+
+	type pluginLocation struct {
+		root     int
+		index    []int
+		accepted bool
+	}
+
+	locations := []pluginLocation{}
+	for _, entry := range app.plugins {
+		root, index, ok := cur.pluginLocation(entry)
+		if ok {
+			locations = append(locations, pluginLocation{root: root, index: index, accepted: accepted[entry]})
+		}
+	}
+
+	// custom config is never modified in place, readers observe either the previous or the next one
+	custom := app.Config()
+
+	// readers holding RLockConfig observe either the previous or the new config, never a mix of them
+	app.cfgMu.Lock()
+	defer app.cfgMu.Unlock()
+
+	err := next.walk(func(f configField) error {
+		inCustom := f.root == 0 && f.index[0] == customField.Index[0]
+		apply := inCustom || f.root == 0 && f.index[0] != pluginsField.Index[0]
+
+		for _, loc := range locations {
+			if loc.root == f.root && len(f.index) >= len(loc.index) && slices.Equal(loc.index, f.index[:len(loc.index)]) {
+				apply = loc.accepted
+				break
+			}
+		}
+
+		value := cur.field(f)
+		if inCustom {
+			value = fieldByIndex(reflect.ValueOf(custom).Elem(), f.index[1:])
+		}
+
+		if reflect.DeepEqual(value.Interface(), f.value.Interface()) {
+			return nil
+		}
+
+		if !apply {
+			app.Warn().Str("field", f.key()).Any("old", value.Interface()).Any("new", f.value.Interface()).Msg("config: value change requires restart")
+			return nil
+		}
+
+		app.Log().Str("field", f.key()).Any("old", value.Interface()).Any("new", f.value.Interface()).Msg("config: value changed")
+		if !inCustom {
+			value.Set(f.value)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	app.custom.Store(&next.cfg.Custom)
+	app.setDebug(app.cfg.Debug)
+	return nil
+}
+
+// RLockConfig locks the config for reading until RUnlockConfig is called, so that Reload
+// cannot change it in the meantime. Goroutines which read fields of reconfigurable plugins
+// (see Plugins) while the app is running should hold the lock, otherwise they may observe
+// a partially applied reload. Config does not need the lock.
+// The lock must not be held while calling Reload.
+func (app *AppCtx[T, U]) RLockConfig() {
+	app.cfgMu.RLock()
+}
+
+// RUnlockConfig releases the lock taken by RLockConfig.
+func (app *AppCtx[T, U]) RUnlockConfig() {
+	app.cfgMu.RUnlock()
+}
+
+// findPluginConfig locates plugin inside of the current config and returns
+// its counterpart from the next config.
+func findPluginConfig[T any, U any](cur, next *appCfg[T, U], plugin AppPlugin[T, U]) (AppPlugin[T, U], bool) {
+	pv := reflect.ValueOf(plugin)
+	if pv.Kind() != reflect.Pointer || pv.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	_, index, ok := findStruct(reflect.ValueOf(cur).Elem(), pv.Type().Elem(), pv.Pointer())
+	if !ok {
+		return nil, false
+	}
+
+	nextPlugin, ok := fieldByIndex(reflect.ValueOf(next).Elem(), index).Addr().Interface().(AppPlugin[T, U])
+	return nextPlugin, ok
+}
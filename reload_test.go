@@ -0,0 +1,168 @@
+package appctx
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reloadTestPlugin[T any, U any] struct {
+	Limit int `yaml:"limit"`
+
+	accept bool
+	seen   int
+}
+
+func (pl *reloadTestPlugin[T, U]) PluginName() string {
+	return "reload"
+}
+
+func (pl *reloadTestPlugin[T, U]) PluginReconfigure(_ *AppCtx[T, U], prev, next AppPlugin[T, U]) error {
+	if prev != pl {
+		return assert.AnError
+	}
+
+	pl.seen = next.(*reloadTestPlugin[T, U]).Limit //nolint:forcetypeassert
+	if !pl.accept {
+		return assert.AnError
+	}
+
+	return nil
+}
+
+type staticTestPlugin[T any, U any] struct {
+	Size int `yaml:"size"`
+}
+
+func (pl *staticTestPlugin[T, U]) PluginName() string {
+	return "static"
+}
+
+func TestAppReload(t *testing.T) {
+	type appConfig struct {
+		Message string `yaml:"message"`
+	}
+
+	type appPlugins struct {
+		Reload reloadTestPlugin[appConfig, appPlugins] `yaml:",inline"`
+		Static staticTestPlugin[appConfig, appPlugins] `yaml:",inline"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("message: one\nlimit: 1\nsize: 1\n"), 0o600))
+
+	os.Args = []string{os.Args[0], "-c", configFile}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, appPlugins]("Test App", "1.0.0")
+	app.RegisterPlugin(&app.P().Reload)
+	app.RegisterPlugin(&app.P().Static)
+	app.Run(func(app *AppCtx[appConfig, appPlugins]) error {
+		prev := app.C()
+
+		require.NoError(t, os.WriteFile(configFile, []byte("message: two\nlimit: 2\nsize: 2\n"), 0o600))
+
+		require.Error(t, app.Reload())
+		assert.Equal(t, 2, app.P().Reload.seen)
+		assert.Equal(t, "one", app.C().Message)
+		assert.Equal(t, 1, app.P().Reload.Limit)
+
+		app.P().Reload.accept = true
+		require.NoError(t, app.Reload())
+		assert.Equal(t, "two", app.C().Message)
+		assert.Equal(t, "one", prev.Message)
+		assert.Equal(t, 2, app.P().Reload.Limit)
+		assert.True(t, app.P().Reload.accept)
+
+		// plugins without PluginReconfigure keep their config until restart
+		assert.Equal(t, 1, app.P().Static.Size)
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
+
+func TestAppReloadConcurrentReads(t *testing.T) {
+	type appConfig struct {
+		Message string `yaml:"message"`
+		Count   int    `yaml:"count"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("message: m0\ncount: 0\n"), 0o600))
+
+	os.Args = []string{os.Args[0], "-c", configFile}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, struct{}]("Test App", "1.0.0")
+	app.Run(func(app *AppCtx[appConfig, struct{}]) error {
+		done := make(chan struct{})
+		mismatches := make(chan string, 1)
+
+		go func() {
+			defer close(done)
+
+			for app.Ready() {
+				cfg := app.C()
+				msg, count := cfg.Message, cfg.Count
+
+				if msg != fmt.Sprintf("m%d", count) {
+					mismatches <- msg
+					return
+				}
+			}
+		}()
+
+		for i := 1; i <= 20; i++ {
+			require.NoError(t, os.WriteFile(configFile, []byte(fmt.Sprintf("message: m%d\ncount: %d\n", i, i)), 0o600))
+			require.NoError(t, app.Reload())
+		}
+
+		app.Stop()
+		<-done
+
+		select {
+		case msg := <-mismatches:
+			t.Errorf("observed partially applied config: %v", msg)
+		default:
+		}
+
+		assert.Equal(t, 20, app.C().Count)
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
+
+func TestAppReloadDebug(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("debug: false\n"), 0o600))
+
+	os.Args = []string{os.Args[0], "-c", configFile}
+	defer resetCommandlineFlags()
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.SetLogOutput(io.Discard)
+	app.Run(func(app *AppCtx[struct{}, struct{}]) error {
+		assert.False(t, app.Debug().Enabled())
+
+		require.NoError(t, os.WriteFile(configFile, []byte("debug: true\n"), 0o600))
+		require.NoError(t, app.Reload())
+		assert.True(t, app.Debug().Enabled())
+
+		require.NoError(t, os.WriteFile(configFile, []byte("debug: false\n"), 0o600))
+		require.NoError(t, app.Reload())
+		assert.False(t, app.Debug().Enabled())
+		assert.True(t, app.Log().Enabled())
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
@@ -0,0 +1,64 @@
+package appctx
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret holds a sensitive config value, e.g. a password. It is decoded from YAML,
+// environment variables and flags as T, but is rendered as "[REDACTED]" by fmt, zerolog,
+// JSON and YAML. The value itself is only available through Reveal.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret wraps the value into Secret.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Reveal returns the secret value.
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+func (s Secret[T]) String() string {
+	return redacted
+}
+
+func (s Secret[T]) GoString() string {
+	return redacted
+}
+
+// Format makes all fmt verbs print the redacted placeholder, including %d and %+v.
+func (s Secret[T]) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(redacted))
+}
+
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+func (s Secret[T]) MarshalYAML() (any, error) {
+	return redacted, nil
+}
+
+// UnmarshalText parses the value given by environment variable or flag.
+func (s *Secret[T]) UnmarshalText(text []byte) error {
+	return setFromString(reflect.ValueOf(&s.value).Elem(), string(text))
+}
+
+func (s *Secret[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &s.value)
+}
+
+func (s *Secret[T]) UnmarshalYAML(unmarshal func(any) error) error {
+	return unmarshal(&s.value)
+}
@@ -0,0 +1,104 @@
+package appctx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretRedaction(t *testing.T) {
+	s := NewSecret("hunter2")
+	v := struct {
+		Password Secret[string] `json:"password" yaml:"password"`
+	}{s}
+
+	assert.Equal(t, "hunter2", s.Reveal())
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%d", "%x"} {
+		assert.NotContains(t, fmt.Sprintf(format, v), "hunter2", format)
+	}
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password": "[REDACTED]"}`, string(data))
+
+	data, err = yaml.Marshal(v)
+	require.NoError(t, err)
+	assert.Equal(t, "password: \"[REDACTED]\"\n", string(data))
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	logger.Info().Any("v", v).Stringer("s", s).Interface("p", &s).Msg("")
+	assert.NotContains(t, buf.String(), "hunter2")
+
+	var decoded Secret[int]
+	require.NoError(t, json.Unmarshal([]byte("42"), &decoded))
+	assert.Equal(t, 42, decoded.Reveal())
+}
+
+func TestSecretConfig(t *testing.T) {
+	type appConfig struct {
+		Password Secret[string] `yaml:"password"`
+		Token    Secret[string] `yaml:"token"`
+		APIKey   Secret[string] `yaml:"api_key" flag:"api-key"`
+		PIN      Secret[int]    `yaml:"pin"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("password: file-secret\npin: 1234\n"), 0o600))
+
+	t.Setenv("APP_TOKEN", "env:secret")
+
+	os.Args = []string{os.Args[0], "-c", configFile, "--api-key", "flag-secret"}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, struct{}]("Test App", "1.0.0")
+	app.SetEnvPrefix("APP")
+	app.Run(func(app *AppCtx[appConfig, struct{}]) error {
+		assert.Equal(t, "file-secret", app.C().Password.Reveal())
+		assert.Equal(t, "env:secret", app.C().Token.Reveal())
+		assert.Equal(t, "flag-secret", app.C().APIKey.Reveal())
+		assert.Equal(t, 1234, app.C().PIN.Reveal())
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
+
+func TestSecretReloadLog(t *testing.T) {
+	type appConfig struct {
+		Token Secret[string] `yaml:"token"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("token: old-token\n"), 0o600))
+
+	os.Args = []string{os.Args[0], "-c", configFile}
+	defer resetCommandlineFlags()
+
+	var out bytes.Buffer
+
+	app := NewApp[appConfig, struct{}]("Test App", "1.0.0")
+	app.SetLogOutput(&out)
+	app.Run(func(app *AppCtx[appConfig, struct{}]) error {
+		require.NoError(t, os.WriteFile(configFile, []byte("token: new-token\n"), 0o600))
+		require.NoError(t, app.Reload())
+		assert.Equal(t, "new-token", app.C().Token.Reveal())
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+	assert.Contains(t, out.String(), "config: value changed")
+	assert.NotContains(t, out.String(), "old-token")
+	assert.NotContains(t, out.String(), "new-token")
+}
@@ -0,0 +1,186 @@
+package appctx
+
+import (
+	"reflect"
+	"strings"
+)
+
+type pluginOptions struct {
+	section string
+}
+
+// PluginOption configures registration of a plugin.
+type PluginOption func(o *pluginOptions)
+
+// WithSection makes the plugin read its config from its own YAML section with the given name
+// (e.g. "admin: {port: 8080}") instead of sharing the top level with other inline plugins.
+// The plugin should not be a part of the app config then. The name also replaces PluginName
+// as the plugin identity (in dependencies, timeouts, flags and health reports),
+// so that several instances of the same plugin can be registered.
+func WithSection(name string) PluginOption {
+	return func(o *pluginOptions) {
+		o.section = name
+	}
+}
+
+// configSet is the config being loaded: the app config itself and sections
+// of plugins registered with WithSection, which live outside of it.
+type configSet[T any, U any] struct {
+	cfg      *appCfg[T, U]
+	sections []configSection
+}
+
+type configSection struct {
+	name  string
+	value reflect.Value
+}
+
+// liveConfig returns config set which refers to the config in use.
+func (app *AppCtx[T, U]) liveConfig() *configSet[T, U] {
+	set := &configSet[T, U]{cfg: app.cfg}
+
+	for _, entry := range app.plugins {
+		if entry.section != "" {
+			set.sections = append(set.sections, configSection{
+				name:  entry.section,
+				value: reflect.ValueOf(entry.plugin).Elem(),
+			})
+		}
+	}
+
+	return set
+}
+
+// defaultConfig returns a fresh copy of config defaults, i.e. values set before config was loaded.
+func (app *AppCtx[T, U]) defaultConfig() *configSet[T, U] {
+	cfg := app.cfgDefaults
+	set := &configSet[T, U]{cfg: &cfg}
+
+	for _, entry := range app.plugins {
+		if entry.section != "" {
+			value := reflect.New(entry.defaults.Type()).Elem()
+			value.Set(entry.defaults)
+
+			set.sections = append(set.sections, configSection{name: entry.section, value: value})
+		}
+	}
+
+	return set
+}
+
+// saveDefaults remembers current config as defaults for reloads.
+func (app *AppCtx[T, U]) saveDefaults() {
+	app.cfgDefaults = *app.cfg
+
+	for _, entry := range app.plugins {
+		if entry.section != "" {
+			v := reflect.ValueOf(entry.plugin).Elem()
+			entry.defaults = reflect.New(v.Type()).Elem()
+			entry.defaults.Set(v)
+		}
+	}
+}
+
+// rootValue returns the struct which fields with the given root index belong to.
+func (s *configSet[T, U]) rootValue(root int) reflect.Value {
+	if root == 0 {
+		return reflect.ValueOf(s.cfg).Elem()
+	}
+
+	return s.sections[root-1].value
+}
+
+// walk calls fn for each leaf value of the app config and plugin sections.
+// Paths of section fields start with the section name.
+func (s *configSet[T, U]) walk(fn func(f configField) error) error {
+	err := walkConfig(s.cfg, fn)
+	if err != nil {
+		return err
+	}
+
+	for i, section := range s.sections {
+		err = walkConfigStruct(section.value, []string{section.name}, nil, func(f configField) error {
+			f.root = i + 1
+			return fn(f)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// find returns the config field which ptr points to.
+func (s *configSet[T, U]) find(ptr any) (configField, bool) {
+	pv := reflect.ValueOf(ptr)
+	if pv.Kind() != reflect.Pointer {
+		return configField{}, false
+	}
+
+	var found configField
+	ok := false
+
+	_ = s.walk(func(f configField) error {
+		if f.value.UnsafeAddr() == pv.Pointer() && f.value.Type() == pv.Type().Elem() {
+			found, ok = f, true
+		}
+
+		return nil
+	})
+
+	return found, ok
+}
+
+// field returns the field of this set which corresponds to the field of another set.
+func (s *configSet[T, U]) field(f configField) reflect.Value {
+	return fieldByIndex(s.rootValue(f.root), f.index)
+}
+
+// structPath returns dotted YAML path of the struct with the given root and index.
+func (s *configSet[T, U]) structPath(root int, index []int) string {
+	path := configPath(s.rootValue(root).Type(), index)
+	if root == 0 {
+		return path
+	}
+
+	return strings.TrimSuffix(s.sections[root-1].name+"."+path, ".")
+}
+
+// pluginLocation returns root and index of the plugin config inside of the config set.
+func (s *configSet[T, U]) pluginLocation(entry *appPluginEntry[T, U]) (root int, index []int, ok bool) {
+	if entry.section != "" {
+		for i, section := range s.sections {
+			if section.name == entry.section {
+				return i + 1, nil, true
+			}
+		}
+
+		return 0, nil, false
+	}
+
+	pv := reflect.ValueOf(entry.plugin)
+	if pv.Kind() != reflect.Pointer || pv.Elem().Kind() != reflect.Struct {
+		return 0, nil, false
+	}
+
+	_, index, ok = findStruct(reflect.ValueOf(s.cfg).Elem(), pv.Type().Elem(), pv.Pointer())
+	return 0, index, ok
+}
+
+// pluginConfig locates config of the plugin inside of the current config and returns
+// its counterpart from the next config.
+func (app *AppCtx[T, U]) pluginConfig(cur, next *configSet[T, U], entry *appPluginEntry[T, U]) (AppPlugin[T, U], bool) {
+	if entry.section == "" {
+		return findPluginConfig(cur.cfg, next.cfg, entry.plugin)
+	}
+
+	for i, section := range cur.sections {
+		if section.name == entry.section {
+			plugin, ok := next.sections[i].value.Addr().Interface().(AppPlugin[T, U])
+			return plugin, ok
+		}
+	}
+
+	return nil, false
+}
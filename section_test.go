@@ -0,0 +1,129 @@
+package appctx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sectionTestPlugin[T any, U any] struct {
+	Port    int           `yaml:"port" validate:"min=1"`
+	Timeout time.Duration `yaml:"timeout"`
+
+	started bool
+	seen    int
+}
+
+func (pl *sectionTestPlugin[T, U]) PluginName() string {
+	return "server"
+}
+
+func (pl *sectionTestPlugin[T, U]) PluginInstantiate(_ *AppCtx[T, U]) error {
+	pl.Port = 80
+	return nil
+}
+
+func (pl *sectionTestPlugin[T, U]) PluginFlags(ac *AppCtx[T, U]) {
+	ac.Flag("port", &pl.Port, pl.Port, "port to listen on")
+}
+
+func (pl *sectionTestPlugin[T, U]) PluginStart(_ *AppCtx[T, U]) error {
+	pl.started = true
+	return nil
+}
+
+func (pl *sectionTestPlugin[T, U]) PluginReconfigure(_ *AppCtx[T, U], _, next AppPlugin[T, U]) error {
+	pl.seen = next.(*sectionTestPlugin[T, U]).Port //nolint:forcetypeassert
+	return nil
+}
+
+func TestPluginSections(t *testing.T) {
+	type appConfig struct {
+		Timeout time.Duration `yaml:"timeout"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte(
+		"timeout: 1s\npublic:\n  port: 8080\n  timeout: 2s\nadmin:\n  port: 9090\n",
+	), 0o600))
+
+	t.Setenv("APP_ADMIN_TIMEOUT", "3s")
+
+	os.Args = []string{os.Args[0], "-c", configFile, "--public.port", "8081"}
+	defer resetCommandlineFlags()
+
+	public := &sectionTestPlugin[appConfig, struct{}]{}
+	admin := &sectionTestPlugin[appConfig, struct{}]{}
+
+	app := NewApp[appConfig, struct{}]("Test App", "1.0.0")
+	app.SetEnvPrefix("APP")
+	app.RegisterPlugin(public, WithSection("public"))
+	app.RegisterPlugin(admin, WithSection("admin"))
+	app.Run(func(app *AppCtx[appConfig, struct{}]) error {
+		assert.Equal(t, time.Second, app.C().Timeout)
+		assert.True(t, public.started)
+		assert.Equal(t, 8081, public.Port)
+		assert.Equal(t, 2*time.Second, public.Timeout)
+		assert.True(t, admin.started)
+		assert.Equal(t, 9090, admin.Port)
+		assert.Equal(t, 3*time.Second, admin.Timeout)
+
+		assert.Equal(t, SourceFlag, app.ConfigSource("public.port"))
+		assert.Equal(t, SourceFile, app.ConfigSource("admin.port"))
+		assert.Equal(t, SourceEnv, app.ConfigSource("admin.timeout"))
+
+		require.NoError(t, os.WriteFile(configFile, []byte("public:\n  port: 1\nadmin:\n  port: 9091\n"), 0o600))
+		require.NoError(t, app.Reload())
+		assert.Equal(t, 9091, admin.seen)
+		assert.Equal(t, 9091, admin.Port)
+		assert.Equal(t, 8081, public.Port)
+		assert.Zero(t, public.Timeout)
+		assert.True(t, admin.started)
+
+		assert.Contains(t, app.Health().Plugins, "public")
+		assert.Contains(t, app.Health().Plugins, "admin")
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
+
+func TestPluginSectionErrors(t *testing.T) {
+	type appConfig struct {
+		Admin string `yaml:"admin"`
+	}
+
+	for name, tc := range map[string]struct {
+		config string
+		err    string
+	}{
+		"unknown key":  {config: "public:\n  prot: 1\n", err: `:2:3: unknown key "public.prot" (did you mean "port"?)`},
+		"validation":   {config: "public:\n  port: 0\n", err: "public.port: must be at least 1"},
+		"claimed key":  {config: "admin: x\n", err: `config key "admin" is claimed by both Custom.Admin and plugin "admin"`},
+		"invalid type": {config: "public:\n  port: [1]\n", err: `decoding YAML section "public"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yml")
+			require.NoError(t, os.WriteFile(configFile, []byte(tc.config), 0o600))
+
+			os.Args = []string{os.Args[0], "-c", configFile}
+			defer resetCommandlineFlags()
+
+			app := NewApp[appConfig, struct{}]("Test App", "1.0.0")
+			app.RegisterPlugin(&sectionTestPlugin[appConfig, struct{}]{}, WithSection("public"))
+			if name == "claimed key" {
+				app.RegisterPlugin(&sectionTestPlugin[appConfig, struct{}]{}, WithSection("admin"))
+			}
+
+			err := app.RunE(func(_ *AppCtx[appConfig, struct{}]) error {
+				return nil
+			})
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
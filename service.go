@@ -0,0 +1,118 @@
+package appctx
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type appService[T any, U any] struct {
+	name string
+	fn   func(ac *AppCtx[T, U]) error
+	main bool
+}
+
+type appServices[T any, U any] struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	pending []appService[T, U]
+	running bool
+	errs    []error
+}
+
+// AddService registers a named service which runs concurrently with other services
+// and the Run callback after all plugins have started. Services may be registered
+// by plugins during PluginStart as well. The app keeps running until all services return;
+// the first service to fail stops the app, and errors of all failed services are reported together.
+func (app *AppCtx[T, U]) AddService(name string, fn func(ac *AppCtx[T, U]) error) {
+	s := appService[T, U]{
+		name: name,
+		fn:   fn,
+	}
+
+	app.services.mu.Lock()
+	defer app.services.mu.Unlock()
+
+	if app.services.running {
+		app.startService(s)
+		return
+	}
+
+	app.services.pending = append(app.services.pending, s)
+}
+
+// runServices runs all registered services along with the callback (if any) and waits for them.
+// Unlike other services, return from callback stops the whole app.
+func (app *AppCtx[T, U]) runServices(callback func(ctx *AppCtx[T, U]) error) error {
+	app.services.mu.Lock()
+
+	if callback != nil {
+		app.services.pending = append(app.services.pending, appService[T, U]{
+			name: "main",
+			fn:   callback,
+			main: true,
+		})
+	}
+
+	if len(app.services.pending) == 0 {
+		app.services.mu.Unlock()
+		return errors.New("nothing to run: no callback and no services were provided")
+	}
+
+	app.services.running = true
+	for _, s := range app.services.pending {
+		app.startService(s)
+	}
+	app.services.pending = nil
+
+	app.services.mu.Unlock()
+
+	app.services.wg.Wait()
+
+	app.services.mu.Lock()
+	defer app.services.mu.Unlock()
+
+	app.services.running = false
+	return errors.Join(app.services.errs...)
+}
+
+// startService must be called with services mutex locked.
+func (app *AppCtx[T, U]) startService(s appService[T, U]) {
+	app = app.root
+
+	app.services.wg.Add(1)
+	go func() {
+		defer app.services.wg.Done()
+
+		if !s.main {
+			app.Debug().Str("service", s.name).Msg("starting service")
+		}
+
+		err := app.callRecovering(s.fn)
+		app.logPanic(err, "service", s.name)
+
+		switch {
+		case s.main:
+			// callback error is reported as is, so that the app can fail with context error
+			if err != nil {
+				app.failService(err)
+				app.StopWithCause(err)
+			} else {
+				app.StopWithCause(ErrFinished)
+			}
+		case err != nil && !(errors.Is(err, context.Canceled) && app.Err() != nil):
+			err = &ServiceError{Name: s.name, Err: err}
+			app.failService(err)
+			app.StopWithCause(err)
+		default:
+			app.Debug().Str("service", s.name).Msg("service finished")
+		}
+	}()
+}
+
+func (app *AppCtx[T, U]) failService(err error) {
+	app.services.mu.Lock()
+	defer app.services.mu.Unlock()
+
+	app.services.errs = append(app.services.errs, err)
+}
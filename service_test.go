@@ -0,0 +1,62 @@
+package appctx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppServices(t *testing.T) {
+	resetCommandlineFlags()
+
+	ran := map[string]bool{}
+	ch := make(chan struct{})
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.AddService("first", func(_ *AppCtx[struct{}, struct{}]) error {
+		close(ch)
+		return nil
+	})
+	app.AddService("second", func(_ *AppCtx[struct{}, struct{}]) error {
+		<-ch
+		ran["second"] = true
+		return nil
+	})
+	app.DisableConfig()
+	app.Run(nil)
+
+	require.False(t, app.hasError)
+	assert.True(t, ran["second"])
+}
+
+func TestAppServiceFailure(t *testing.T) {
+	resetCommandlineFlags()
+
+	canceled := false
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.AddService("failing", func(_ *AppCtx[struct{}, struct{}]) error {
+		return assert.AnError
+	})
+	app.AddService("waiting", func(app *AppCtx[struct{}, struct{}]) error {
+		<-app.Done()
+		canceled = true
+		return app.Err()
+	})
+	app.DisableConfig()
+	app.Run(nil)
+
+	require.True(t, app.hasError)
+	assert.True(t, canceled)
+}
+
+func TestAppNothingToRun(t *testing.T) {
+	resetCommandlineFlags()
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.DisableConfig()
+	app.Run(nil)
+
+	require.True(t, app.hasError)
+}
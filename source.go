@@ -0,0 +1,50 @@
+package appctx
+
+// ConfigSource is a configuration layer which the value of a config field came from.
+// Layers override each other in the order they are declared.
+type ConfigSource int
+
+const (
+	SourceDefault ConfigSource = iota
+	SourceFile
+	SourceEnv
+	SourceFlag
+)
+
+func (s ConfigSource) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	default:
+		return "unknown"
+	}
+}
+
+// ConfigSource returns the layer which the effective value of the config field came from.
+// Fields are referenced by their YAML paths, e.g. "timeouts.start".
+func (app *AppCtx[T, U]) ConfigSource(key string) ConfigSource {
+	sources := app.sources.Load()
+	if sources == nil {
+		return SourceDefault
+	}
+
+	return (*sources)[key]
+}
+
+// ConfigSources returns layers which effective values of all config fields came from.
+func (app *AppCtx[T, U]) ConfigSources() map[string]ConfigSource {
+	res := map[string]ConfigSource{}
+
+	_ = app.liveConfig().walk(func(f configField) error {
+		res[f.key()] = app.ConfigSource(f.key())
+		return nil
+	})
+
+	return res
+}
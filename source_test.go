@@ -0,0 +1,90 @@
+package appctx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sourceTestPlugin[T any, U any] struct {
+	Workers int `yaml:"workers" flag:"workers,w,number of workers"`
+}
+
+func (pl *sourceTestPlugin[T, U]) PluginName() string {
+	return "source"
+}
+
+func (pl *sourceTestPlugin[T, U]) PluginInstantiate(_ *AppCtx[T, U]) error {
+	pl.Workers = 4
+	return nil
+}
+
+func TestAppConfigSources(t *testing.T) {
+	type appConfig struct {
+		Host  string `yaml:"host" flag:",,host to listen on"`
+		Port  int    `yaml:"port" flag:"port,p"`
+		Name  string `yaml:"name"`
+		Level string `yaml:"log_level" flag:""`
+	}
+
+	type appPlugins struct {
+		Source sourceTestPlugin[appConfig, appPlugins] `yaml:",inline"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("host: file\nport: 80\nlog_level: info\n"), 0o600))
+
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_LOG_LEVEL", "warn")
+
+	os.Args = []string{os.Args[0], "-c", configFile, "-p", "9090", "--host", "flag"}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, appPlugins]("Test App", "1.0.0")
+	app.SetEnvPrefix("APP")
+	app.RegisterPlugin(&app.P().Source)
+	app.Run(func(app *AppCtx[appConfig, appPlugins]) error {
+		assert.Equal(t, appConfig{Host: "flag", Port: 9090, Level: "warn"}, *app.C())
+		assert.Equal(t, 4, app.P().Source.Workers)
+
+		assert.Equal(t, SourceFlag, app.ConfigSource("host"))
+		assert.Equal(t, SourceFlag, app.ConfigSource("port"))
+		assert.Equal(t, SourceEnv, app.ConfigSource("log_level"))
+		assert.Equal(t, SourceDefault, app.ConfigSource("workers"))
+		assert.Equal(t, SourceDefault, app.ConfigSource("name"))
+		assert.Equal(t, SourceDefault, app.ConfigSources()["timeouts.start"])
+
+		return nil
+	})
+
+	require.False(t, app.hasError)
+	assert.Contains(t, getFlagHelp(app.flags), "\n\t-p, --port: ")
+	assert.Contains(t, getFlagHelp(app.flags), "\n\t--host: host to listen on")
+	assert.Contains(t, getFlagHelp(app.flags), "\n\t--log-level: ")
+	assert.Contains(t, getFlagHelp(app.flags), "\n\t-w, --workers: number of workers")
+}
+
+func TestAppConfigFileSource(t *testing.T) {
+	type appConfig struct {
+		Name string `yaml:"name"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("name: file\ntimeouts:\n  start: 1s\n"), 0o600))
+
+	os.Args = []string{os.Args[0], "-c", configFile}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, struct{}]("Test App", "1.0.0")
+	app.Run(func(app *AppCtx[appConfig, struct{}]) error {
+		assert.Equal(t, SourceFile, app.ConfigSource("name"))
+		assert.Equal(t, SourceFile, app.ConfigSource("timeouts.start"))
+		assert.Equal(t, SourceDefault, app.ConfigSource("timeouts.stop"))
+		return nil
+	})
+
+	require.False(t, app.hasError)
+}
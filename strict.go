@@ -0,0 +1,193 @@
+package appctx
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+)
+
+// DisableStrictConfig makes unknown keys in config files silently ignored instead of failing.
+func (app *AppCtx[T, U]) DisableStrictConfig() {
+	app.noStrictConfig = true
+}
+
+// configSchema is a tree of YAML keys which the config accepts.
+type configSchema struct {
+	children map[string]*configSchema
+	owners   map[string]string
+
+	// any marks values which are not checked, e.g. maps or custom unmarshalers
+	any bool
+}
+
+// newConfigSchema builds schema of the struct type. It fails if two fields,
+// e.g. of different inline plugin structs, claim the same key.
+func newConfigSchema(t reflect.Type) (*configSchema, error) {
+	s := &configSchema{}
+	return s, s.addStruct(t, nil, "")
+}
+
+func (s *configSchema) addStruct(t reflect.Type, path []string, owner string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if isConfigLeaf(t) {
+		s.any = true
+		return nil
+	}
+
+	if s.children == nil {
+		s.children = map[string]*configSchema{}
+		s.owners = map[string]string{}
+	}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if (!field.IsExported() && !field.Anonymous) || yamlTag(field) == "-" {
+			continue
+		}
+
+		fieldOwner := strings.TrimPrefix(owner+"."+field.Name, ".")
+
+		name, inline := yamlFieldName(field)
+		if inline {
+			err := s.addStruct(field.Type, path, fieldOwner)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		fieldPath := append(slices.Clip(path), name)
+		if prev, ok := s.owners[name]; ok {
+			return fmt.Errorf("config key \"%v\" is claimed by both %v and %v", strings.Join(fieldPath, "."), prev, fieldOwner)
+		}
+
+		child := &configSchema{}
+		err := child.addStruct(field.Type, fieldPath, fieldOwner)
+		if err != nil {
+			return err
+		}
+
+		s.children[name] = child
+		s.owners[name] = fieldOwner
+	}
+
+	return nil
+}
+
+// addSection adds section of a plugin registered with WithSection.
+func (s *configSchema) addSection(name string, t reflect.Type) error {
+	owner := "plugin \"" + name + "\""
+	if prev, ok := s.owners[name]; ok {
+		return fmt.Errorf("config key \"%v\" is claimed by both %v and %v", name, prev, owner)
+	}
+
+	child := &configSchema{}
+	err := child.addStruct(t, []string{name}, owner)
+	if err != nil {
+		return err
+	}
+
+	s.children[name] = child
+	s.owners[name] = owner
+	return nil
+}
+
+// checkKeys reports keys of the YAML node which are not known to the schema.
+func (s *configSchema) checkKeys(node ast.Node, path []string, file string) []error {
+	if s.any {
+		return nil
+	}
+
+	switch n := node.(type) {
+	case *ast.AnchorNode:
+		return s.checkKeys(n.Value, path, file)
+	case *ast.TagNode:
+		return s.checkKeys(n.Value, path, file)
+	}
+
+	mapping, ok := toYAMLMapping(node)
+	if !ok {
+		return nil
+	}
+
+	errs := []error{}
+	for _, value := range mapping.Values {
+		key := value.Key.GetToken().Value
+		if key == "<<" {
+			continue
+		}
+
+		keyPath := append(slices.Clip(path), key)
+
+		child, ok := s.children[key]
+		if !ok {
+			pos := value.Key.GetToken().Position
+			err := fmt.Errorf("%v:%d:%d: unknown key \"%v\"", file, pos.Line, pos.Column, strings.Join(keyPath, "."))
+
+			if suggestion := s.suggest(key); suggestion != "" {
+				err = fmt.Errorf("%w (did you mean \"%v\"?)", err, suggestion)
+			}
+
+			errs = append(errs, err)
+			continue
+		}
+
+		errs = append(errs, child.checkKeys(value.Value, keyPath, file)...)
+	}
+
+	return errs
+}
+
+// suggest returns the known key which is the closest to the given one, if it is close enough.
+func (s *configSchema) suggest(key string) string {
+	best, bestDist := "", max(2, len(key)/3)+1
+
+	keys := make([]string, 0, len(s.children))
+	for known := range s.children {
+		keys = append(keys, known)
+	}
+	slices.Sort(keys)
+
+	for _, known := range keys {
+		dist := editDistance(key, known)
+		if dist < bestDist {
+			best, bestDist = known, dist
+		}
+	}
+
+	return best
+}
+
+// editDistance returns Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,97 @@
+package appctx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type strictTestPlugin[T any, U any] struct {
+	DatabaseURL string `yaml:"database_url"`
+}
+
+func (pl *strictTestPlugin[T, U]) PluginName() string {
+	return "strict"
+}
+
+func TestAppStrictConfig(t *testing.T) {
+	type appConfig struct {
+		Server struct {
+			Port int `yaml:"port"`
+		} `yaml:"server"`
+		Labels map[string]string `yaml:"labels"`
+	}
+
+	type appPlugins struct {
+		Strict strictTestPlugin[appConfig, appPlugins] `yaml:",inline"`
+	}
+
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"config.yml": "databse_url: postgres://db\nlabels:\n  anything: goes\nserver:\n  prot: 80\n",
+		"extra.yml":  "timeouts:\n  strat: 1s\nunrelated: true\n",
+	})
+
+	for _, strict := range []bool{true, false} {
+		os.Args = []string{os.Args[0], "-c", filepath.Join(dir, "config.yml"), "-c", filepath.Join(dir, "extra.yml")}
+
+		app := NewApp[appConfig, appPlugins]("Test App", "1.0.0")
+		app.RegisterPlugin(&app.P().Strict)
+		if !strict {
+			app.DisableStrictConfig()
+		}
+
+		err := app.RunE(func(_ *AppCtx[appConfig, appPlugins]) error {
+			return nil
+		})
+
+		if !strict {
+			require.NoError(t, err)
+			continue
+		}
+
+		require.Error(t, err)
+		assert.Equal(t, ExitCodeConfig, ExitCodeOf(err))
+		assert.Contains(t, err.Error(), filepath.Join(dir, "config.yml")+`:1:1: unknown key "databse_url" (did you mean "database_url"?)`)
+		assert.Contains(t, err.Error(), filepath.Join(dir, "config.yml")+`:5:3: unknown key "server.prot" (did you mean "port"?)`)
+		assert.Contains(t, err.Error(), filepath.Join(dir, "extra.yml")+`:2:3: unknown key "timeouts.strat" (did you mean "start"?)`)
+		assert.Contains(t, err.Error(), filepath.Join(dir, "extra.yml")+`:3:1: unknown key "unrelated"`)
+		assert.NotContains(t, err.Error(), "anything")
+	}
+
+	resetCommandlineFlags()
+}
+
+func TestAppDuplicateConfigKeys(t *testing.T) {
+	type appConfig struct {
+		DatabaseURL string `yaml:"database_url"`
+	}
+
+	type appPlugins struct {
+		Strict strictTestPlugin[appConfig, appPlugins] `yaml:",inline"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("database_url: postgres://db\n"), 0o600))
+
+	os.Args = []string{os.Args[0], "-c", configFile}
+	defer resetCommandlineFlags()
+
+	app := NewApp[appConfig, appPlugins]("Test App", "1.0.0")
+	app.RegisterPlugin(&app.P().Strict)
+
+	err := app.RunE(func(_ *AppCtx[appConfig, appPlugins]) error {
+		return nil
+	})
+	require.ErrorContains(t, err, "config key \"database_url\" is claimed by both Custom.DatabaseURL and Plugins.Strict.DatabaseURL")
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("port", "port"))
+	assert.Equal(t, 2, editDistance("prot", "port"))
+	assert.Equal(t, 1, editDistance("databse_url", "database_url"))
+	assert.Equal(t, 4, editDistance("", "port"))
+}
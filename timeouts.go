@@ -0,0 +1,172 @@
+package appctx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+type appTimeouts struct {
+	Start    time.Duration                `yaml:"start"`
+	Stop     time.Duration                `yaml:"stop"`
+	Shutdown time.Duration                `yaml:"shutdown"`
+	Plugins  map[string]appPluginTimeouts `yaml:"plugins"`
+}
+
+type appPluginTimeouts struct {
+	Start time.Duration `yaml:"start"`
+	Stop  time.Duration `yaml:"stop"`
+}
+
+// SetTimeouts sets default timeouts of PluginStart and PluginStop hooks
+// and the overall shutdown timeout. Zero value disables the corresponding timeout.
+// Values from the "timeouts" section of the config file take precedence.
+func (app *AppCtx[_, _]) SetTimeouts(start, stop, shutdown time.Duration) {
+	app.cfg.Timeouts.Start = start
+	app.cfg.Timeouts.Stop = stop
+	app.cfg.Timeouts.Shutdown = shutdown
+}
+
+// SetPluginTimeouts overrides start and stop timeouts of a single plugin.
+func (app *AppCtx[_, _]) SetPluginTimeouts(name string, start, stop time.Duration) {
+	if app.cfg.Timeouts.Plugins == nil {
+		app.cfg.Timeouts.Plugins = map[string]appPluginTimeouts{}
+	}
+
+	app.cfg.Timeouts.Plugins[name] = appPluginTimeouts{
+		Start: start,
+		Stop:  stop,
+	}
+}
+
+func (app *AppCtx[_, _]) pluginTimeouts(name string) (start, stop time.Duration) {
+	app.cfgMu.RLock()
+	defer app.cfgMu.RUnlock()
+
+	start, stop = app.cfg.Timeouts.Start, app.cfg.Timeouts.Stop
+
+	if t, ok := app.cfg.Timeouts.Plugins[name]; ok {
+		setDefault(&t.Start, start)
+		setDefault(&t.Stop, stop)
+		start, stop = t.Start, t.Stop
+	}
+
+	return start, stop
+}
+
+// callWithTimeout runs a plugin hook with a copy of app whose context expires after the timeout.
+// If the hook does not return in time, an error is returned without waiting for it.
+// Panics in the hook are converted into PanicError.
+func (app *AppCtx[T, U]) callWithTimeout(timeout time.Duration, hook func(ac *AppCtx[T, U]) error) error {
+	return app.callWatchingTimeout(timeout, hook, nil)
+}
+
+// callWatchingTimeout is like callWithTimeout, but if the hook is abandoned, abandoned
+// is called in background with the result of the hook once it finally returns.
+func (app *AppCtx[T, U]) callWatchingTimeout(timeout time.Duration, hook func(ac *AppCtx[T, U]) error, abandoned func(err error)) error {
+	if timeout <= 0 {
+		return app.callRecovering(hook)
+	}
+
+	hookApp, done := app.WithTimeout(timeout)
+	defer done()
+
+	res := make(chan error, 1)
+	go func() {
+		res <- hookApp.callRecovering(hook)
+	}()
+
+	select {
+	case err := <-res:
+		return err
+	case <-hookApp.Done():
+		if abandoned != nil {
+			go func() {
+				abandoned(<-res)
+			}()
+		}
+
+		if errors.Is(hookApp.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %v", timeout)
+		}
+
+		return hookApp.Err()
+	}
+}
+
+// shutdownWatchdog forcibly exits the process if shutdown takes longer than the timeout.
+type shutdownWatchdog struct {
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+
+	timeout   time.Duration
+	onTimeout func()
+	stopWatch func() bool
+}
+
+// watchShutdown creates a watchdog which is armed as soon as the app context is done.
+func (app *AppCtx[_, _]) watchShutdown() *shutdownWatchdog {
+	w := &shutdownWatchdog{
+		timeout: app.cfg.Timeouts.Shutdown,
+	}
+
+	w.onTimeout = func() {
+		app.dumpGoroutines(w.timeout)
+		app.flushLogs()
+		osExit(ExitCodeShutdownTimeout)
+	}
+
+	w.stopWatch = context.AfterFunc(app.Context, w.arm)
+	return w
+}
+
+func (w *shutdownWatchdog) arm() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timer != nil || w.stopped || w.timeout <= 0 {
+		return
+	}
+
+	w.timer = time.AfterFunc(w.timeout, w.onTimeout)
+}
+
+func (w *shutdownWatchdog) stop() {
+	w.stopWatch()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopped = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+func (app *AppCtx[_, _]) dumpGoroutines(timeout time.Duration) {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	if app.hasLogger {
+		app.Logger().Error().
+			Dur("timeout", timeout).
+			Str("goroutines", string(buf)).
+			Msg("shutdown timed out, forcing exit")
+	} else {
+		fmt.Println("ERROR: shutdown timed out, forcing exit\n" + string(buf))
+	}
+}
@@ -0,0 +1,148 @@
+package appctx
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hangingTestPlugin[T any, U any] struct {
+	release chan struct{}
+}
+
+func (pl *hangingTestPlugin[T, U]) PluginName() string {
+	return "hanging"
+}
+
+func (pl *hangingTestPlugin[T, U]) PluginStart(ac *AppCtx[T, U]) error {
+	<-ac.Done()
+	<-pl.release
+	return nil
+}
+
+func TestPluginStartTimeout(t *testing.T) {
+	resetCommandlineFlags()
+
+	pl := &hangingTestPlugin[struct{}, struct{}]{release: make(chan struct{})}
+	defer close(pl.release)
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(pl)
+	app.SetPluginTimeouts("hanging", 10*time.Millisecond, 0)
+	app.DisableConfig()
+	app.Run(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+
+	require.True(t, app.hasError)
+}
+
+type slowStopTestPlugin[T any, U any] struct {
+	release chan struct{}
+}
+
+func (pl *slowStopTestPlugin[T, U]) PluginName() string {
+	return "slow"
+}
+
+func (pl *slowStopTestPlugin[T, U]) PluginStop(_ *AppCtx[T, U]) {
+	<-pl.release
+}
+
+type lateStartTestPlugin[T any, U any] struct {
+	release chan struct{}
+	stopped atomic.Bool
+}
+
+func (pl *lateStartTestPlugin[T, U]) PluginName() string {
+	return "late"
+}
+
+func (pl *lateStartTestPlugin[T, U]) PluginStart(_ *AppCtx[T, U]) error {
+	<-pl.release
+	return nil
+}
+
+func (pl *lateStartTestPlugin[T, U]) PluginStop(_ *AppCtx[T, U]) {
+	pl.stopped.Store(true)
+}
+
+func TestPluginLateStart(t *testing.T) {
+	resetCommandlineFlags()
+
+	pl := &lateStartTestPlugin[struct{}, struct{}]{release: make(chan struct{})}
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(pl)
+	app.SetPluginTimeouts("late", 10*time.Millisecond, 0)
+	app.DisableConfig()
+	err := app.RunE(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+
+	require.Error(t, err)
+	assert.False(t, pl.stopped.Load())
+
+	// the start hook ignores its context and succeeds after the deadline
+	close(pl.release)
+	assert.Eventually(t, pl.stopped.Load, time.Second, time.Millisecond)
+}
+
+func TestShutdownWatchdog(t *testing.T) {
+	resetCommandlineFlags()
+
+	exitCode := -1
+	pl := &slowStopTestPlugin[struct{}, struct{}]{release: make(chan struct{})}
+
+	osExit = func(code int) {
+		exitCode = code
+		close(pl.release)
+	}
+	defer func() {
+		osExit = os.Exit
+	}()
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(pl)
+	app.SetTimeouts(0, 0, 10*time.Millisecond)
+	app.DisableConfig()
+	app.Run(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+
+	assert.Equal(t, ExitCodeShutdownTimeout, exitCode)
+}
+
+type stopContextTestPlugin[T any, U any] struct {
+	errs []error
+}
+
+func (pl *stopContextTestPlugin[T, U]) PluginName() string {
+	return "stop-context"
+}
+
+func (pl *stopContextTestPlugin[T, U]) PluginStop(ac *AppCtx[T, U]) {
+	pl.errs = append(pl.errs, ac.Err())
+}
+
+func TestPluginStopContext(t *testing.T) {
+	resetCommandlineFlags()
+
+	for _, stop := range []time.Duration{0, time.Second} {
+		pl := &stopContextTestPlugin[struct{}, struct{}]{}
+
+		app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+		app.RegisterPlugin(pl)
+		app.SetTimeouts(0, stop, 0)
+		app.DisableConfig()
+		app.Run(func(_ *AppCtx[struct{}, struct{}]) error {
+			return nil
+		})
+
+		assert.Equal(t, []error{nil}, pl.errs, "stop timeout %v", stop)
+	}
+}
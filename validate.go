@@ -0,0 +1,274 @@
+package appctx
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError lists all violations found in the config.
+type ValidationError struct {
+	Violations []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		lines = append(lines, v.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		errs = append(errs, v)
+	}
+
+	return errs
+}
+
+// FieldError is a violation of a config field or struct referenced by its YAML path.
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// configValidator is implemented by config structs (including plugins) and values which check themselves.
+type configValidator interface {
+	Validate() error
+}
+
+// validateConfig checks config fields against their `validate:"..."` tags and calls Validate methods
+// of the config structs. Supported rules are required, min=N, max=N, oneof=a b c and url;
+// min and max limit numbers, durations and lengths of strings, slices and maps.
+// Configs of disabled plugins are not validated.
+func (app *AppCtx[T, U]) validateConfig(set *configSet[T, U]) error {
+	// configs of plugins are located in the set the same way as in the live config
+	live := app.liveConfig()
+
+	skipped := map[uintptr]bool{}
+	for _, entry := range app.plugins {
+		if entry.getState() != pluginDisabled {
+			continue
+		}
+
+		for i, section := range live.sections {
+			if section.name == entry.section {
+				skipped[set.sections[i].value.UnsafeAddr()] = true
+			}
+		}
+
+		pv := reflect.ValueOf(entry.plugin)
+		if entry.section != "" || pv.Kind() != reflect.Pointer || pv.Elem().Kind() != reflect.Struct {
+			continue
+		}
+
+		_, index, ok := findStruct(live.rootValue(0), pv.Type().Elem(), pv.Pointer())
+		if ok {
+			skipped[fieldByIndex(set.rootValue(0), index).UnsafeAddr()] = true
+		}
+	}
+
+	violations := []*FieldError{}
+	visited := map[string]bool{}
+
+	_ = set.walk(func(f configField) error {
+		// validate structs containing the field, from outer to inner ones
+		for i := range len(f.index) {
+			v := fieldByIndex(set.rootValue(f.root), f.index[:i])
+			if skipped[v.UnsafeAddr()] {
+				return nil
+			}
+
+			key := fmt.Sprint(f.root, f.index[:i])
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+
+			validator, ok := v.Addr().Interface().(configValidator)
+			if !ok {
+				continue
+			}
+
+			err := validator.Validate()
+			if err != nil {
+				violations = append(violations, &FieldError{Path: set.structPath(f.root, f.index[:i]), Err: err})
+			}
+		}
+
+		if validator, ok := f.value.Addr().Interface().(configValidator); ok {
+			err := validator.Validate()
+			if err != nil {
+				violations = append(violations, &FieldError{Path: f.key(), Err: err})
+			}
+		}
+
+		for _, err := range validateField(f) {
+			violations = append(violations, &FieldError{Path: f.key(), Err: err})
+		}
+
+		return nil
+	})
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	return nil
+}
+
+// configPath returns dotted YAML path of the field with the given index.
+func configPath(t reflect.Type, index []int) string {
+	path := []string{}
+
+	for _, i := range index {
+		field := t.Field(i)
+
+		name, inline := yamlFieldName(field)
+		if !inline {
+			path = append(path, name)
+		}
+
+		t = field.Type
+	}
+
+	return strings.Join(path, ".")
+}
+
+func validateField(f configField) []error {
+	tag, ok := f.field.Tag.Lookup("validate")
+	if !ok || tag == "" {
+		return nil
+	}
+
+	v := revealValue(f.value)
+
+	errs := []error{}
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+
+		err := validateRule(v, name, arg)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// revealValue returns the value wrapped by Secret or pointed to by a pointer.
+func revealValue(v reflect.Value) reflect.Value {
+	if reveal := v.MethodByName("Reveal"); reveal.IsValid() && reveal.Type().NumIn() == 0 && reveal.Type().NumOut() == 1 {
+		v = reveal.Call(nil)[0]
+	}
+
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func validateRule(v reflect.Value, name, arg string) error {
+	switch name {
+	case "required":
+		if v.IsZero() {
+			return errors.New("is required")
+		}
+	case "min", "max":
+		if v.Kind() == reflect.Pointer {
+			return nil
+		}
+
+		n, ok := measure(v)
+		if !ok {
+			return fmt.Errorf("%v is not supported for %v", name, v.Type())
+		}
+
+		bound, err := parseBound(v, arg)
+		if err != nil {
+			return fmt.Errorf("invalid %v rule: %w", name, err)
+		}
+
+		if name == "min" && n < bound {
+			return fmt.Errorf("must be at least %v", arg)
+		}
+
+		if name == "max" && n > bound {
+			return fmt.Errorf("must be at most %v", arg)
+		}
+	case "oneof":
+		if v.IsZero() {
+			return nil
+		}
+
+		if !slices.Contains(strings.Fields(arg), fmt.Sprint(v.Interface())) {
+			return fmt.Errorf("must be one of: %v", strings.Join(strings.Fields(arg), ", "))
+		}
+	case "url":
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("url is not supported for %v", v.Type())
+		}
+
+		if v.IsZero() {
+			return nil
+		}
+
+		u, err := url.Parse(v.String())
+		if err != nil || u.Scheme == "" || u.Host == "" && u.Opaque == "" {
+			return errors.New("must be a valid URL")
+		}
+	default:
+		return fmt.Errorf("unknown validation rule \"%v\"", name)
+	}
+
+	return nil
+}
+
+// measure returns the number compared by min and max rules: the value itself for numbers,
+// or the length for strings, slices and maps.
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+func parseBound(v reflect.Value, arg string) (float64, error) {
+	if v.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(arg)
+		if err == nil {
+			return float64(d), nil
+		}
+	}
+
+	return strconv.ParseFloat(arg, 64)
+}
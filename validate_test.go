@@ -0,0 +1,104 @@
+package appctx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateTestPlugin[T any, U any] struct {
+	URL    Secret[string] `yaml:"url" validate:"required,url"`
+	Shards int            `yaml:"shards"`
+}
+
+func (pl *validateTestPlugin[T, U]) PluginName() string {
+	return "validate"
+}
+
+func (pl *validateTestPlugin[T, U]) Validate() error {
+	if pl.Shards%2 != 0 {
+		return errors.New("shards must be even")
+	}
+
+	return nil
+}
+
+type validateTestServer struct {
+	Port    int           `yaml:"port" validate:"min=1,max=65535"`
+	Mode    string        `yaml:"mode" validate:"oneof=dev prod"`
+	Timeout time.Duration `yaml:"timeout" validate:"min=1s"`
+	Hosts   []string      `yaml:"hosts" validate:"min=1"`
+}
+
+func TestAppValidation(t *testing.T) {
+	type appConfig struct {
+		Server validateTestServer `yaml:"server"`
+		Name   string             `yaml:"name" validate:"required"`
+	}
+
+	type appPlugins struct {
+		Validate validateTestPlugin[appConfig, appPlugins] `yaml:",inline"`
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte(
+		"server:\n  port: 70000\n  mode: test\n  timeout: 10ms\nurl: not a url\nshards: 3\n",
+	), 0o600))
+
+	os.Args = []string{os.Args[0], "-c", configFile}
+	defer resetCommandlineFlags()
+
+	started := false
+
+	app := NewApp[appConfig, appPlugins]("Test App", "1.0.0")
+	app.RegisterPlugin(&app.P().Validate)
+	err := app.RunE(func(_ *AppCtx[appConfig, appPlugins]) error {
+		started = true
+		return nil
+	})
+
+	require.Error(t, err)
+	assert.False(t, started)
+	assert.Equal(t, ExitCodeConfig, ExitCodeOf(err))
+
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+
+	messages := []string{}
+	for _, v := range verr.Violations {
+		messages = append(messages, v.Error())
+	}
+
+	assert.Equal(t, []string{
+		"server.port: must be at most 65535",
+		"server.mode: must be one of: dev, prod",
+		"server.timeout: must be at least 1s",
+		"server.hosts: must be at least 1",
+		"name: is required",
+		"shards must be even",
+		"url: must be a valid URL",
+	}, messages)
+}
+
+func TestAppValidationSkipsDisabledPlugins(t *testing.T) {
+	type appPlugins struct {
+		Validate validateTestPlugin[struct{}, appPlugins] `yaml:",inline"`
+	}
+
+	resetCommandlineFlags()
+	os.Args = append(os.Args, "version")
+
+	app := NewApp[struct{}, appPlugins]("Test App", "1.0.0")
+	app.RegisterPlugin(&app.P().Validate)
+	app.Command("version", "print version", func(_ *AppCtx[struct{}, appPlugins]) error {
+		return nil
+	}, WithPlugins())
+	app.DisableConfig()
+
+	require.NoError(t, app.RunE(nil))
+}
@@ -0,0 +1,159 @@
+package appctx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RestartPolicy defines whether a worker started with Go is restarted after it exits.
+type RestartPolicy int
+
+const (
+	// RestartNever runs the worker only once.
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure restarts the worker if it returns an error or panics.
+	RestartOnFailure
+	// RestartAlways restarts the worker whenever it exits.
+	RestartAlways
+)
+
+const (
+	defaultMinWorkerBackoff = 1 * time.Second
+	defaultMaxWorkerBackoff = 1 * time.Minute
+)
+
+type workerOptions struct {
+	restart    RestartPolicy
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	fatal      bool
+}
+
+// WorkerOption configures a worker started with Go.
+type WorkerOption func(o *workerOptions)
+
+// WithRestart sets restart policy of the worker. Default policy is RestartNever.
+func WithRestart(policy RestartPolicy) WorkerOption {
+	return func(o *workerOptions) {
+		o.restart = policy
+	}
+}
+
+// WithBackoff sets delays between worker restarts. The delay starts at minDelay
+// and doubles after each consecutive restart up to maxDelay.
+func WithBackoff(minDelay, maxDelay time.Duration) WorkerOption {
+	return func(o *workerOptions) {
+		o.minBackoff = minDelay
+		o.maxBackoff = maxDelay
+	}
+}
+
+// WithFatal makes the app stop if the worker fails and is not going to be restarted.
+// The failure is then reported as an error of the whole app.
+func WithFatal() WorkerOption {
+	return func(o *workerOptions) {
+		o.fatal = true
+	}
+}
+
+type appWorkers struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+func (w *appWorkers) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.errs = append(w.errs, err)
+}
+
+// wait waits for all workers to exit and returns errors of fatal workers.
+func (w *appWorkers) wait() error {
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return errors.Join(w.errs...)
+}
+
+// Go starts a supervised background worker. Panics in the worker are recovered
+// and treated as failures. Workers receive the app itself rather than the copy Go
+// was called on, and are expected to exit once the app context is done;
+// the app waits for all of them before stopping plugins.
+// Go should be called only after Run has started (e.g. from the Run callback or PluginStart).
+func (app *AppCtx[T, U]) Go(name string, fn func(ac *AppCtx[T, U]) error, opts ...WorkerOption) {
+	o := workerOptions{
+		minBackoff: defaultMinWorkerBackoff,
+		maxBackoff: defaultMaxWorkerBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	root := app.root
+
+	root.workers.wg.Add(1)
+	go func() {
+		defer root.workers.wg.Done()
+		root.superviseWorker(name, fn, o)
+	}()
+}
+
+func (app *AppCtx[T, U]) superviseWorker(name string, fn func(ac *AppCtx[T, U]) error, o workerOptions) {
+	backoff := o.minBackoff
+
+	for {
+		app.Debug().Str("worker", name).Msg("starting worker")
+
+		t1 := time.Now()
+		err := app.callRecovering(fn)
+		app.logPanic(err, "worker", name)
+
+		if app.Err() != nil {
+			// app is shutting down, so exit reason does not matter anymore
+			if err != nil && !errors.Is(err, context.Canceled) {
+				app.Warn().Err(err).Str("worker", name).Msg("worker failed during shutdown")
+			}
+
+			return
+		}
+
+		if o.restart == RestartNever || (o.restart == RestartOnFailure && err == nil) {
+			if err == nil {
+				app.Debug().Str("worker", name).Msg("worker finished")
+				return
+			}
+
+			app.Error(err).Str("worker", name).Msg("worker failed")
+
+			if o.fatal {
+				err = &WorkerError{Name: name, Err: err}
+				app.workers.fail(err)
+				app.StopWithCause(err)
+			}
+
+			return
+		}
+
+		// worker which ran long enough is considered healthy, so backoff starts over
+		if time.Since(t1) > o.maxBackoff {
+			backoff = o.minBackoff
+		}
+
+		app.Warn().Err(err).Str("worker", name).Dur("backoff", backoff).Msg("worker exited, restarting")
+
+		select {
+		case <-app.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, o.maxBackoff)
+	}
+}
@@ -0,0 +1,93 @@
+package appctx
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerRestart(t *testing.T) {
+	resetCommandlineFlags()
+
+	var runs atomic.Int32
+	done := make(chan struct{})
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.DisableConfig()
+	app.Run(func(app *AppCtx[struct{}, struct{}]) error {
+		app.Go("flaky", func(_ *AppCtx[struct{}, struct{}]) error {
+			if runs.Add(1) < 3 {
+				panic("flaky worker")
+			}
+
+			close(done)
+			return nil
+		}, WithRestart(RestartOnFailure), WithBackoff(time.Millisecond, 10*time.Millisecond))
+
+		<-done
+		return nil
+	})
+
+	require.False(t, app.hasError)
+	assert.EqualValues(t, 3, runs.Load())
+}
+
+func TestWorkerFatal(t *testing.T) {
+	resetCommandlineFlags()
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.DisableConfig()
+	app.Run(func(app *AppCtx[struct{}, struct{}]) error {
+		app.Go("fatal", func(_ *AppCtx[struct{}, struct{}]) error {
+			return assert.AnError
+		}, WithFatal())
+
+		<-app.Done()
+		return nil
+	})
+
+	require.True(t, app.hasError)
+}
+
+type workerTestPlugin[T any, U any] struct {
+	workerDone atomic.Bool
+	doneOnStop bool
+}
+
+func (pl *workerTestPlugin[T, U]) PluginName() string {
+	return "worker"
+}
+
+func (pl *workerTestPlugin[T, U]) PluginStart(ac *AppCtx[T, U]) error {
+	ac.Go("background", func(ac *AppCtx[T, U]) error {
+		<-ac.Done()
+		time.Sleep(10 * time.Millisecond)
+		pl.workerDone.Store(true)
+		return ac.Err()
+	})
+
+	return nil
+}
+
+func (pl *workerTestPlugin[T, U]) PluginStop(_ *AppCtx[T, U]) {
+	pl.doneOnStop = pl.workerDone.Load()
+}
+
+func TestWorkersStopBeforePlugins(t *testing.T) {
+	resetCommandlineFlags()
+
+	pl := &workerTestPlugin[struct{}, struct{}]{}
+
+	app := NewApp[struct{}, struct{}]("Test App", "1.0.0")
+	app.RegisterPlugin(pl)
+	app.DisableConfig()
+	app.Run(func(_ *AppCtx[struct{}, struct{}]) error {
+		return nil
+	})
+
+	require.False(t, app.hasError)
+	assert.True(t, pl.doneOnStop)
+}